@@ -6,9 +6,12 @@ package cc
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -16,13 +19,19 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"text/tabwriter"
 	"time"
 
+	"github.com/cquestor/cc/logger"
+	"github.com/cquestor/cc/metrics"
 	"github.com/cquestor/cc/orm"
 	"github.com/cquestor/cc/router"
+	"github.com/cquestor/cc/tracing"
 	"github.com/cquestor/cc/watcher"
+	"gopkg.in/yaml.v2"
 )
 
 // J json结构
@@ -31,12 +40,40 @@ type J map[string]any
 // Engine Web引擎
 type Engine struct {
 	*RouteGroup
-	config   *AppConfig
-	router   router.IRouter
-	handlers map[string]map[string]IHandler
-	options  map[string]any
-	database *orm.Engine
-	groups   []*RouteGroup
+	config         *AppConfig
+	router         router.IRouter
+	handlers       map[string]map[string]IHandler
+	options        map[string]any
+	database       *orm.Engine
+	groups         []*RouteGroup
+	logFile        *logger.RotateWriter
+	stopLogReopen  func()
+	proxies        atomic.Pointer[[]netip.Prefix]
+	registry       *metrics.Registry
+	httpMetrics    *metrics.HTTPMetrics
+	tracer         *tracing.Tracer
+	healthMu       sync.RWMutex
+	healthChecks   []healthCheck
+	draining       atomic.Bool
+	mu             sync.Mutex
+	server         *http.Server
+	redirectServer *http.Server
+	tlsConfig      *tls.Config
+	certReloader   *certReloader
+	listeners      []*boundListener
+	prepareOnce    sync.Once
+	prepareErr     error
+	bootOnce       sync.Once
+	bootErr        error
+	serveErr       error
+	startHooks     []func(context.Context) error
+	shutdownHooks  []func(context.Context) error
+	shutdownOnce   sync.Once
+	shutdownErr    error
+	stopped        chan struct{}
+	snapshot       atomic.Pointer[AppConfig]
+	reloadMu       sync.Mutex
+	changeHooks    []func(*ConfigChange)
 }
 
 // RouteGroup 分组路由
@@ -49,15 +86,19 @@ type RouteGroup struct {
 }
 
 type (
-	CAppConfig   []byte // 项目配置
-	CTLSCertFile string // TLS证书
-	CTLSKeyFile  string // TLS密钥
+	CAppConfig   []byte   // 项目配置
+	CTLSCertFile string   // TLS证书
+	CTLSKeyFile  string   // TLS密钥
+	CArgs        []string // 命令行参数，默认为 os.Args[1:]
+	CConfigPath  string   // 配置文件路径，格式依据扩展名识别，可被 --config 参数覆盖
 )
 
 const (
-	optAppConfig = "AppConfig"
-	optCertPath  = "CertPath"
-	optKeyPath   = "KeyPath"
+	optAppConfig  = "AppConfig"
+	optCertPath   = "CertPath"
+	optKeyPath    = "KeyPath"
+	optArgs       = "Args"
+	optConfigPath = "ConfigPath"
 )
 
 var DEFAULT_BUILD_NAME = "main"
@@ -69,6 +110,7 @@ func New() *Engine {
 		router:   router.NewRouter(),
 		handlers: make(map[string]map[string]IHandler),
 		options:  make(map[string]any),
+		stopped:  make(chan struct{}),
 	}
 	engine.RouteGroup = &RouteGroup{engine: engine}
 	engine.groups = []*RouteGroup{engine.RouteGroup}
@@ -86,28 +128,34 @@ func (group *RouteGroup) Group(prefix string) *RouteGroup {
 	return newGroup
 }
 
-// Run 启动 Web Server
+// Run 启动 Web Server，命令行入口，出错时退出进程
 func (engine *Engine) Run(options ...any) {
 	banner()
 	changeOS()
-	engine.parseOptions(options...)
-	if err := engine.parseConfig(); err != nil {
-		LogErrf("Parse config err: %v\n", err)
+	if err := engine.prepare(context.Background(), options...); err != nil {
+		LogErr(err)
 		os.Exit(1)
 	}
-	if err := engine.initConfig(); err != nil {
-		LogErrf("Init config err: %v\n", err)
-		os.Exit(1)
+	args := os.Args[1:]
+	if options, ok := engine.options[optArgs].(CArgs); ok {
+		args = options
 	}
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", engine.config.Port),
-		Handler:      engine,
-		ReadTimeout:  time.Duration(engine.config.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(engine.config.WriteTimeout) * time.Second,
-		IdleTimeout:  time.Duration(engine.config.IdleTimeout) * time.Second,
+	if _, ok := parseFlags(args)["dump-config"]; ok {
+		fmt.Print(engine.config.Redacted())
+		return
 	}
 	if os.Getenv("GONE_ROUTINE") != "" || engine.config.Production {
-		engine.serverReady(server)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := engine.Start(ctx); err != nil {
+			LogErrf("Could not start the server: %v", err)
+			os.Exit(1)
+		}
+		if err := engine.Wait(); err != nil {
+			LogErrf("Server stopped with error: %v", err)
+			os.Exit(1)
+		}
+		LogInfo("Server stopped")
 	} else {
 		dirpath, _ := os.Getwd()
 		watch, err := watcher.NewWatcher(dirpath)
@@ -202,49 +250,48 @@ func (engine *Engine) handleWatch(watch *watcher.Watcher, needBuild chan<- int)
 	}
 }
 
-// serverReady 启动服务
-func (engine *Engine) serverReady(server *http.Server) {
-	done := make(chan struct{}, 1)
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	go engine.shutdown(server, quit, done)
-	LogInfo("Server is ready to handle requests at", server.Addr)
-	engine.start(server)
-	<-done
-	LogInfo("Server stopped")
+// ParseConfig 分层加载配置
+func (engine *Engine) parseConfig() error {
+	if err := engine.loadConfig(engine.config); err != nil {
+		return err
+	}
+	engine.snapshot.Store(engine.config)
+	return nil
 }
 
-// start 启动服务 http/https
-func (engine *Engine) start(server *http.Server) {
-	if engine.options[optCertPath] != nil && engine.options[optKeyPath] != nil {
-		certPath := engine.options[optCertPath].(CTLSCertFile)
-		keyPath := engine.options[optKeyPath].(CTLSKeyFile)
-		if err := server.ListenAndServeTLS(string(certPath), string(keyPath)); err != nil && err != http.ErrServerClosed {
-			LogErrf("Could not listen https on %s: %v \n", server.Addr, err)
-			os.Exit(1)
-		}
-	} else {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			LogErrf("Could not listen http on %s: %v \n", server.Addr, err)
-			os.Exit(1)
-		}
+// loadConfig 分层加载配置到 config 并校验，启动及热加载共用
+func (engine *Engine) loadConfig(config *AppConfig) error {
+	loader := newConfigLoader(config, engine.options)
+	loader.strict = config.strictType()
+	raw, err := loader.load()
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	// 各层的类型错误已由严格校验定位到文件及行号，此处仅补充覆盖层引入的问题
+	report := &ConfigError{Problems: loader.problems}
+	if err := config.ParseContent(content); len(loader.problems) == 0 {
+		report.add(err)
+	}
+	report.add(config.load(raw, loader))
+	report.add(config.Validate())
+	if err := report.err(); err != nil {
+		return err
 	}
+	LogDebugf("Effective config:\n%s", config.Redacted())
+	return nil
 }
 
-// ParseConfig 读取配置文件
-func (engine *Engine) parseConfig() (err error) {
-	if content, ok := engine.options[optAppConfig]; ok {
-		LogInfo("Loading config from content by provided")
-		err = engine.config.ParseContent(content.(CAppConfig))
-	} else {
-		LogInfof("Loading config from %s\n", DEFAULT_CONFIG_PATH)
-		err = engine.config.ParseFile(DEFAULT_CONFIG_PATH)
-		if err != nil && os.IsNotExist(err) {
-			LogWarn("Local config file not found, using default config")
-			return nil
-		}
+// Config 获取当前生效的项目配置快照，在 Start、Serve 或 Run 之后可用；
+// 热加载后返回新的快照，调用方不应修改返回值
+func (engine *Engine) Config() *AppConfig {
+	if config := engine.snapshot.Load(); config != nil {
+		return config
 	}
-	return err
+	return engine.config
 }
 
 // ParseOptions 解析运行参数
@@ -257,22 +304,91 @@ func (engine *Engine) parseOptions(options ...any) {
 			engine.options[optCertPath] = option
 		case CTLSKeyFile:
 			engine.options[optKeyPath] = option
+		case CArgs:
+			engine.options[optArgs] = option
+		case CConfigPath:
+			engine.options[optConfigPath] = option
 		}
 	}
 }
 
-// setConfig 依据配置进行初始化
-func (engine *Engine) initConfig() error {
-	if engine.config.Database.Source != "" {
+// initConfig 依据配置进行初始化，ctx 结束时停止数据库连接重试
+func (engine *Engine) initConfig(ctx context.Context) error {
+	if err := engine.initLogger(); err != nil {
+		return err
+	}
+	if err := engine.initTLS(); err != nil {
+		return err
+	}
+	if len(engine.config.TrustedProxies) > 0 {
+		if err := engine.SetTrustedProxies(engine.config.TrustedProxies...); err != nil {
+			return err
+		}
+	}
+	if config := engine.config.Database; config.Source != "" {
 		LogInfo("Database source found, connecting to database")
-		if dataEngine, err := orm.NewEngine(engine.config.Database.Source); err != nil {
+		pool := orm.CPool{
+			MaxOpenConns:    config.MaxOpenConns,
+			MaxIdleConns:    config.MaxIdleConns,
+			ConnMaxLifetime: time.Duration(config.ConnMaxLifetime) * time.Second,
+			ConnMaxIdleTime: time.Duration(config.ConnMaxIdleTime) * time.Second,
+		}
+		retry := orm.CRetry{
+			Attempts: config.ConnectRetries,
+			Backoff:  time.Duration(config.ConnectBackoff) * time.Millisecond,
+			OnRetry: func(attempt int, err error) {
+				LogWarnf("Could not connect to database, retrying (%d/%d): %v", attempt, config.ConnectRetries, err)
+			},
+		}
+		options := []any{pool, retry}
+		if config.Driver != "" {
+			options = append(options, orm.CDriver(config.Driver))
+		}
+		if dataEngine, err := orm.NewEngineContext(ctx, config.Source, options...); err != nil {
 			return err
 		} else {
 			engine.database = dataEngine
+			engine.AddHealthCheck("database", dataEngine.DB.PingContext)
 		}
 	} else {
 		LogWarn("Database source not found, you may not be able to use relevant modules")
 	}
+	engine.initMetrics()
+	return engine.initTracing()
+}
+
+// initLogger 依据配置初始化日志
+func (engine *Engine) initLogger() error {
+	level, err := logger.ParseLevel(engine.config.Log.Level)
+	if err != nil {
+		return err
+	}
+	var outputs []*logger.Output
+	switch engine.config.Log.Format {
+	case "json":
+		outputs = append(outputs, logger.NewJSONOutput(os.Stderr))
+	case "text", "":
+		outputs = append(outputs, logger.NewConsoleOutput(os.Stderr))
+	default:
+		return fmt.Errorf("unknown log format: %s", engine.config.Log.Format)
+	}
+	if engine.config.Log.File != "" {
+		file := logger.NewRotateWriter(engine.config.Log.File)
+		file.MaxSize = int64(engine.config.Log.MaxSize) * 1024 * 1024
+		file.MaxBackups = engine.config.Log.MaxBackups
+		file.MaxAge = time.Duration(engine.config.Log.MaxAge) * 24 * time.Hour
+		file.Interval = time.Duration(engine.config.Log.RotateInterval) * time.Hour
+		file.Compress = engine.config.Log.Compress
+		engine.stopLogReopen = file.NotifyReopen()
+		if engine.config.Log.Format == "json" {
+			outputs = append(outputs, logger.NewJSONOutput(file))
+		} else {
+			outputs = append(outputs, logger.NewTextOutput(file))
+		}
+		engine.logFile = file
+	}
+	Log().SetOutputs(outputs...)
+	Log().SetLevel(level)
 	return nil
 }
 
@@ -284,32 +400,77 @@ func (engine *Engine) initWatch(watch *watcher.Watcher) error {
 	return watch.Init()
 }
 
-// shutdown 服务关闭处理
-func (engine *Engine) shutdown(server *http.Server, quit <-chan os.Signal, done chan<- struct{}) {
-	<-quit
+// shutdown 服务关闭处理，依次标记就绪检查失败、等待 health.drain-delay 秒供负载均衡摘除流量、
+// 停止接收请求、执行关闭钩子并释放资源
+func (engine *Engine) shutdown(ctx context.Context) error {
+	engine.mu.Lock()
+	engine.draining.Store(true)
+	started := engine.server != nil
+	engine.mu.Unlock()
 	LogWarn("Server is shutting down...")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	server.SetKeepAlivesEnabled(false)
-	if err := server.Shutdown(ctx); err != nil {
-		LogErrf("Cound not gracefully shutdown the server: %v \n", err)
-		os.Exit(1)
+	if delay := time.Duration(engine.Config().Health.DrainDelay) * time.Second; started && delay > 0 {
+		LogInfof("Draining traffic for %s before closing listeners", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+	var errs []error
+	engine.mu.Lock()
+	servers := []*http.Server{engine.server, engine.redirectServer}
+	engine.mu.Unlock()
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		server.SetKeepAlivesEnabled(false)
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown server: %w", err))
+			server.Close()
+		}
+	}
+	if err := engine.runHooks(ctx, "shutdown", engine.shutdownHooks); err != nil {
+		errs = append(errs, err)
+	}
+	if engine.tracer != nil {
+		if err := engine.tracer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("flush traces: %w", err))
+		}
 	}
 	if engine.database != nil {
 		engine.database.Close()
 		LogInfo("Database closed success")
 	}
-	close(done)
+	if engine.certReloader != nil {
+		engine.certReloader.Close()
+	}
+	if engine.stopLogReopen != nil {
+		engine.stopLogReopen()
+	}
+	if engine.logFile != nil {
+		engine.logFile.Close()
+	}
+	return errors.Join(errs...)
 }
 
 // ServeHTTP 实现 http.Handler 接口
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if engine.serveHealth(w, r) {
+		return
+	}
 	var session *orm.Session
 	if engine.database != nil {
-		session = engine.database.NewSession()
+		session = engine.database.NewSession().WithContext(r.Context())
 	}
 	ctx := NewContext(w, r, session)
+	ctx.engine = engine
+	defer bindRequestLog(ctx)()
+	defer ctx.finish()
 	defer handleErr(ctx)
+	engine.instrument(ctx)
+	engine.trace(ctx)
 	befores, afters := engine.findInterceptor(ctx)
 	if response := handleMiddlewares(ctx, befores); response != nil {
 		response.Invoke(ctx)
@@ -332,6 +493,7 @@ func (engine *Engine) handleHandler(ctx *Context) Response {
 // findHandler 查找处理器
 func (engine *Engine) findHandler(ctx *Context) IHandler {
 	if route, params := engine.router.GetRoute(ctx.Method, ctx.Path); route != "" {
+		ctx.Route = route
 		ctx.Params = params
 		return engine.handlers[ctx.Method][route]
 	}
@@ -0,0 +1,323 @@
+// Package cctest 提供不启动真实服务的进程内测试工具，
+// 通过 httptest 驱动 Engine.ServeHTTP，或直接构造 Context 测试单个处理器及拦截器
+package cctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cquestor/cc"
+)
+
+// Client 测试客户端
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	header  http.Header
+}
+
+// Request 测试请求构造器
+type Request struct {
+	t       testing.TB
+	handler http.Handler
+	method  string
+	path    string
+	query   url.Values
+	header  http.Header
+	cookies []*http.Cookie
+	params  map[string]string
+	body    []byte
+	remote  string
+}
+
+// Response 测试响应，提供链式断言
+type Response struct {
+	*httptest.ResponseRecorder
+	t      testing.TB
+	result cc.Response
+	ran    bool
+}
+
+// New 构造测试客户端，handler 通常为 *cc.Engine
+func New(t testing.TB, handler http.Handler) *Client {
+	return &Client{t: t, handler: handler, header: http.Header{}}
+}
+
+// SetHeader 设置每个请求默认携带的请求头
+func (client *Client) SetHeader(key, value string) *Client {
+	client.header.Set(key, value)
+	return client
+}
+
+// Get 构造 GET 请求
+func (client *Client) Get(path string) *Request {
+	return client.Request(http.MethodGet, path)
+}
+
+// Post 构造 POST 请求
+func (client *Client) Post(path string) *Request {
+	return client.Request(http.MethodPost, path)
+}
+
+// Put 构造 PUT 请求
+func (client *Client) Put(path string) *Request {
+	return client.Request(http.MethodPut, path)
+}
+
+// Patch 构造 PATCH 请求
+func (client *Client) Patch(path string) *Request {
+	return client.Request(http.MethodPatch, path)
+}
+
+// Delete 构造 DELETE 请求
+func (client *Client) Delete(path string) *Request {
+	return client.Request(http.MethodDelete, path)
+}
+
+// Request 构造任意方法的请求
+func (client *Client) Request(method, path string) *Request {
+	req := NewRequest(client.t, method, path)
+	req.handler = client.handler
+	req.header = client.header.Clone()
+	return req
+}
+
+// NewRequest 构造不绑定处理器的请求，用于 Context、Invoke
+func NewRequest(t testing.TB, method, path string) *Request {
+	return &Request{
+		t:      t,
+		method: method,
+		path:   path,
+		query:  url.Values{},
+		header: http.Header{},
+		params: map[string]string{},
+		remote: "192.0.2.1:1234",
+	}
+}
+
+// Query 添加查询参数
+func (req *Request) Query(key, value string) *Request {
+	req.query.Add(key, value)
+	return req
+}
+
+// Header 设置请求头
+func (req *Request) Header(key, value string) *Request {
+	req.header.Set(key, value)
+	return req
+}
+
+// Cookie 添加 Cookie
+func (req *Request) Cookie(cookie *http.Cookie) *Request {
+	req.cookies = append(req.cookies, cookie)
+	return req
+}
+
+// Param 设置路由参数，仅对 Context、Invoke 生效
+func (req *Request) Param(key, value string) *Request {
+	req.params[key] = value
+	return req
+}
+
+// RemoteAddr 设置客户端地址
+func (req *Request) RemoteAddr(addr string) *Request {
+	req.remote = addr
+	return req
+}
+
+// Body 设置原始请求体
+func (req *Request) Body(body []byte) *Request {
+	req.body = body
+	return req
+}
+
+// JSON 设置 JSON 请求体
+func (req *Request) JSON(v any) *Request {
+	req.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		req.t.Fatalf("cctest: marshal json body: %v\n", err)
+	}
+	req.body = body
+	req.header.Set("Content-Type", "application/json")
+	return req
+}
+
+// Form 设置表单请求体
+func (req *Request) Form(values url.Values) *Request {
+	req.body = []byte(values.Encode())
+	req.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// Build 构造 *http.Request
+func (req *Request) Build() *http.Request {
+	target := req.path
+	if len(req.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + req.query.Encode()
+	}
+	r := httptest.NewRequest(req.method, target, bytes.NewReader(req.body))
+	r.RemoteAddr = req.remote
+	for key, values := range req.header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	for _, cookie := range req.cookies {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+// Do 通过处理器执行请求
+func (req *Request) Do() *Response {
+	req.t.Helper()
+	if req.handler == nil {
+		req.t.Fatalf("cctest: request has no handler, use Client to build it\n")
+	}
+	w := httptest.NewRecorder()
+	req.handler.ServeHTTP(w, req.Build())
+	return &Response{ResponseRecorder: w, t: req.t}
+}
+
+// Context 构造不依赖 Engine 的上下文，用于单独测试处理器或拦截器
+func (req *Request) Context() (*cc.Context, *Response) {
+	w := httptest.NewRecorder()
+	ctx := cc.NewContext(w, req.Build(), nil)
+	ctx.Params = req.params
+	return ctx, &Response{ResponseRecorder: w, t: req.t}
+}
+
+// Invoke 以该请求直接执行单个处理器或拦截器，返回 nil 的拦截器可通过 Passed 断言
+func (req *Request) Invoke(handler func(*cc.Context) cc.Response) *Response {
+	ctx, resp := req.Context()
+	resp.result = handler(ctx)
+	resp.ran = true
+	if resp.result != nil {
+		resp.result.Invoke(ctx)
+	}
+	return resp
+}
+
+// Result 获取 Invoke 时处理器返回的响应
+func (resp *Response) Result() cc.Response {
+	return resp.result
+}
+
+// Passed 断言 Invoke 时拦截器返回 nil，即放行请求
+func (resp *Response) Passed() *Response {
+	resp.t.Helper()
+	if !resp.ran || resp.result != nil {
+		resp.t.Fatalf("cctest: expected handler to pass, got %T (status %d)\n", resp.result, resp.Code)
+	}
+	return resp
+}
+
+// Status 断言状态码
+func (resp *Response) Status(code int) *Response {
+	resp.t.Helper()
+	if resp.Code != code {
+		resp.t.Fatalf("cctest: expected status %d, got %d, body: %s\n", code, resp.Code, resp.Body.String())
+	}
+	return resp
+}
+
+// Header 断言响应头
+func (resp *Response) Header(key, value string) *Response {
+	resp.t.Helper()
+	if actual := resp.ResponseRecorder.Header().Get(key); actual != value {
+		resp.t.Fatalf("cctest: expected header %s %q, got %q\n", key, value, actual)
+	}
+	return resp
+}
+
+// BodyEquals 断言响应体
+func (resp *Response) BodyEquals(body string) *Response {
+	resp.t.Helper()
+	if resp.Body.String() != body {
+		resp.t.Fatalf("cctest: expected body %q, got %q\n", body, resp.Body.String())
+	}
+	return resp
+}
+
+// BodyContains 断言响应体包含指定内容
+func (resp *Response) BodyContains(s string) *Response {
+	resp.t.Helper()
+	if !strings.Contains(resp.Body.String(), s) {
+		resp.t.Fatalf("cctest: expected body to contain %q, got %q\n", s, resp.Body.String())
+	}
+	return resp
+}
+
+// JSON 将响应体解析到 v
+func (resp *Response) JSON(v any) *Response {
+	resp.t.Helper()
+	if err := json.Unmarshal(resp.Body.Bytes(), v); err != nil {
+		resp.t.Fatalf("cctest: decode json body: %v, body: %s\n", err, resp.Body.String())
+	}
+	return resp
+}
+
+// JSONPath 断言 JSON 响应中路径对应的值，路径形如 data.users.0.name 或 data.users[0].name
+func (resp *Response) JSONPath(path string, expected any) *Response {
+	resp.t.Helper()
+	var doc any
+	resp.JSON(&doc)
+	actual, ok := lookup(doc, path)
+	if !ok {
+		resp.t.Fatalf("cctest: json path %q not found in %s\n", path, resp.Body.String())
+	}
+	if !reflect.DeepEqual(actual, normalize(expected)) {
+		resp.t.Fatalf("cctest: json path %q expected %v, got %v\n", path, expected, actual)
+	}
+	return resp
+}
+
+// lookup 查找 JSON 路径
+func lookup(doc any, path string) (any, bool) {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			doc = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			doc = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// normalize 将期望值转换为 JSON 解码后的形式，使 1 与 1.0 等价
+func normalize(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
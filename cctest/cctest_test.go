@@ -0,0 +1,50 @@
+package cctest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cquestor/cc"
+	"github.com/cquestor/cc/cctest"
+)
+
+func TestClient(t *testing.T) {
+	c := cc.New()
+	c.Get("/users", func(ctx *cc.Context) cc.Response {
+		ctx.SetHeader("X-Total", "2")
+		return cc.Json(http.StatusOK, cc.J{"data": []cc.J{{"name": "a"}, {"name": ctx.Query("second")}}, "auth": ctx.Header("Authorization")})
+	})
+	client := cctest.New(t, c).SetHeader("Authorization", "Bearer t")
+	client.Get("/users").Query("second", "b").Do().
+		Status(http.StatusOK).
+		Header("X-Total", "2").
+		JSONPath("data[1].name", "b").
+		JSONPath("data.0.name", "a").
+		JSONPath("auth", "Bearer t")
+}
+
+func TestInvoke(t *testing.T) {
+	auth := func(ctx *cc.Context) cc.Response {
+		if ctx.Header("Authorization") == "" {
+			return cc.Code(http.StatusUnauthorized)
+		}
+		return nil
+	}
+	t.Run("interceptor", func(t *testing.T) {
+		cctest.NewRequest(t, http.MethodGet, "/").Invoke(auth).Status(http.StatusUnauthorized)
+		cctest.NewRequest(t, http.MethodGet, "/").Header("Authorization", "Bearer t").Invoke(auth).Passed()
+	})
+	t.Run("handler", func(t *testing.T) {
+		cctest.NewRequest(t, http.MethodGet, "/user/cc").Param("name", "cc").Invoke(func(ctx *cc.Context) cc.Response {
+			return cc.String(http.StatusOK, "hello %s", ctx.Param("name"))
+		}).Status(http.StatusOK).BodyEquals("hello cc")
+	})
+	t.Run("context", func(t *testing.T) {
+		ctx, resp := cctest.NewRequest(t, http.MethodPost, "/").RemoteAddr("203.0.113.7:80").Body([]byte("raw")).Context()
+		if ctx.ClientIP() != "203.0.113.7" || string(ctx.Body()) != "raw" {
+			t.Fatalf("context error: %s %s\n", ctx.ClientIP(), ctx.Body())
+		}
+		ctx.SetHeader("X-Test", "1")
+		resp.Header("X-Test", "1")
+	})
+}
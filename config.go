@@ -1,59 +1,144 @@
 package cc
 
 import (
+	"fmt"
 	"os"
 
 	"gopkg.in/yaml.v2"
 )
 
-// DEFAULT_CONFIG_PATH 默认配置文件地址
+// DEFAULT_CONFIG_PATH 默认配置文件地址，不存在时依次查找 application.yml、application.json、application.toml
 const DEFAULT_CONFIG_PATH = "application.yaml"
 
 // AppConfig 项目配置
 type AppConfig struct {
-	Main         string `yaml:"main"`
-	Port         int    `yaml:"port"`
-	ReadTimeout  int    `yaml:"read-timeout"`
-	WriteTimeout int    `yaml:"write-timeout"`
-	IdleTimeout  int    `yaml:"idle-timeout"`
-	Production   bool   `yaml:"production"`
-	Database     struct {
-		Source       string `yaml:"source"`
-		MaxOpenConns int    `yaml:"max-open-conns"`
-		MaxIdleConns int    `yaml:"max-idle-conns"`
+	Main            string           `yaml:"main"`
+	Port            int              `yaml:"port"`
+	ReadTimeout     int              `yaml:"read-timeout"`
+	WriteTimeout    int              `yaml:"write-timeout"`
+	IdleTimeout     int              `yaml:"idle-timeout"`
+	ShutdownTimeout int              `yaml:"shutdown-timeout"`
+	HookTimeout     int              `yaml:"hook-timeout"`
+	Production      bool             `yaml:"production"`
+	TrustedProxies  []string         `yaml:"trusted-proxies"`
+	Listeners       []ListenerConfig `yaml:"listeners"`
+	TLS             TLSConfig        `yaml:"tls"`
+	HTTP            HTTPConfig       `yaml:"http"`
+	Database        struct {
+		Source          string `yaml:"source"`
+		Driver          string `yaml:"driver"`
+		MaxOpenConns    int    `yaml:"max-open-conns"`
+		MaxIdleConns    int    `yaml:"max-idle-conns"`
+		ConnMaxLifetime int    `yaml:"conn-max-lifetime"`
+		ConnMaxIdleTime int    `yaml:"conn-max-idle-time"`
+		ConnectRetries  int    `yaml:"connect-retries"`
+		ConnectBackoff  int    `yaml:"connect-backoff"`
+		StatsInterval   int    `yaml:"stats-interval"`
 	} `yaml:"database"`
 	Watch struct {
 		Includes []string `yaml:"includes"`
 		Excludes []string `yaml:"excludes"`
 		Debounce int64    `yaml:"debounce"`
 	} `yaml:"watch"`
+	Log struct {
+		Level          string `yaml:"level"`
+		Format         string `yaml:"format"`
+		File           string `yaml:"file"`
+		MaxSize        int    `yaml:"max-size"`
+		MaxBackups     int    `yaml:"max-backups"`
+		MaxAge         int    `yaml:"max-age"`
+		RotateInterval int    `yaml:"rotate-interval"`
+		Compress       bool   `yaml:"compress"`
+	} `yaml:"log"`
+	Metrics struct {
+		Enabled bool   `yaml:"enabled"`
+		Path    string `yaml:"path"`
+	} `yaml:"metrics"`
+	Tracing struct {
+		Enabled     bool    `yaml:"enabled"`
+		ServiceName string  `yaml:"service-name"`
+		Exporter    string  `yaml:"exporter"`
+		Endpoint    string  `yaml:"endpoint"`
+		SampleRatio float64 `yaml:"sample-ratio"`
+	} `yaml:"tracing"`
+	Health struct {
+		Enabled       bool   `yaml:"enabled"`
+		LivenessPath  string `yaml:"liveness-path"`
+		ReadinessPath string `yaml:"readiness-path"`
+		Timeout       int    `yaml:"timeout"`
+		DrainDelay    int    `yaml:"drain-delay"`
+	} `yaml:"health"`
+	Reload struct {
+		Signal bool `yaml:"signal"`
+		Watch  bool `yaml:"watch"`
+	} `yaml:"reload"`
+	raw        map[string]any
+	loader     *configLoader
+	bindings   []binding
+	registered []string
+	decoders   map[string]IDecoder
 }
 
 // NewAppConfig 构造带默认参数的项目配置
 func NewAppConfig() *AppConfig {
 	config := &AppConfig{
-		Main:         "main.go",
-		Port:         9999,
-		ReadTimeout:  5,
-		WriteTimeout: 10,
-		IdleTimeout:  15,
-		Production:   false,
+		Main:            "main.go",
+		Port:            9999,
+		ReadTimeout:     5,
+		WriteTimeout:    10,
+		IdleTimeout:     15,
+		ShutdownTimeout: 30,
+		HookTimeout:     10,
+		Production:      false,
 	}
 	config.Database.Source = ""
 	config.Database.MaxOpenConns = 10
 	config.Database.MaxIdleConns = 5
+	config.Database.ConnMaxLifetime = 1800
+	config.Database.ConnMaxIdleTime = 600
+	config.Database.ConnectBackoff = 500
 	config.Watch.Includes = make([]string, 0)
 	config.Watch.Excludes = make([]string, 0)
 	config.Watch.Debounce = 300
+	config.Log.Level = "info"
+	config.Log.Format = "text"
+	config.Log.MaxSize = 100
+	config.Log.MaxBackups = 7
+	config.Log.MaxAge = 30
+	config.Metrics.Path = "/metrics"
+	config.Tracing.ServiceName = "cc"
+	config.Tracing.Exporter = "stdout"
+	config.Tracing.Endpoint = "http://localhost:4318/v1/traces"
+	config.Tracing.SampleRatio = 1
+	config.HTTP.ReadHeaderTimeout = 5
+	config.HTTP.MaxHeaderBytes = 1 << 20
+	config.TLS.MinVersion = "1.2"
+	config.TLS.ALPN = []string{"h2", "http/1.1"}
+	config.TLS.Reload = true
+	config.Health.Enabled = true
+	config.Health.LivenessPath = "/healthz"
+	config.Health.ReadinessPath = "/readyz"
+	config.Health.Timeout = 3
 	return config
 }
 
-// ParseFile 解析配置文件
+// ParseFile 解析配置文件，格式依据扩展名识别
 func (config *AppConfig) ParseFile(path string) error {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
+	decoder, err := config.decoder(path)
+	if err != nil {
+		return err
+	}
+	layer, err := decoder.Decode(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if content, err = yaml.Marshal(layer); err != nil {
+		return err
+	}
 	return yaml.Unmarshal(content, &config)
 }
 
@@ -2,21 +2,28 @@ package cc
 
 import (
 	"bytes"
+	"crypto/x509"
 	"io"
 	"mime/multipart"
 	"net/http"
 
+	"github.com/cquestor/cc/logger"
 	"github.com/cquestor/cc/orm"
 )
 
 // Context 上下文
 type Context struct {
-	session *orm.Session
-	Req     *http.Request
-	Writer  http.ResponseWriter
-	Method  string
-	Path    string
-	Params  map[string]string
+	engine    *Engine
+	session   *orm.Session
+	Req       *http.Request
+	Writer    http.ResponseWriter
+	Method    string
+	Path      string
+	Route     string // 匹配到的路由模式，如 /user/:name
+	Params    map[string]string
+	nonce     string
+	requestID string
+	finishs   []func()
 }
 
 // NewContext 新建上下文
@@ -95,6 +102,52 @@ func (ctx *Context) SetMaxFileSize(v int64) error {
 	return ctx.Req.ParseMultipartForm(v)
 }
 
+// Nonce 获取本次请求的 CSP nonce
+func (ctx *Context) Nonce() string {
+	return ctx.nonce
+}
+
+// SetNonce 设置本次请求的 CSP nonce
+func (ctx *Context) SetNonce(nonce string) {
+	ctx.nonce = nonce
+}
+
+// RequestID 获取请求 ID
+func (ctx *Context) RequestID() string {
+	return ctx.requestID
+}
+
+// SetRequestID 设置请求 ID，并附加到请求上下文的日志字段中
+func (ctx *Context) SetRequestID(id string) {
+	ctx.requestID = id
+	ctx.Req = ctx.Req.WithContext(logger.NewContext(ctx.Req.Context(), "request_id", id))
+}
+
+// Logger 获取携带请求日志字段 (如 request_id) 的日志记录器
+func (ctx *Context) Logger() *logger.Logger {
+	return Log().WithContext(ctx.Req.Context())
+}
+
+// ClientCert 获取经过校验的客户端证书 (mTLS)，未提供或未校验时返回 nil
+func (ctx *Context) ClientCert() *x509.Certificate {
+	if ctx.Req.TLS == nil || len(ctx.Req.TLS.VerifiedChains) == 0 || len(ctx.Req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return ctx.Req.TLS.VerifiedChains[0][0]
+}
+
+// OnFinish 添加请求处理完成后的回调，按添加的相反顺序执行
+func (ctx *Context) OnFinish(f func()) {
+	ctx.finishs = append(ctx.finishs, f)
+}
+
+// finish 执行请求处理完成后的回调
+func (ctx *Context) finish() {
+	for i := len(ctx.finishs) - 1; i >= 0; i-- {
+		ctx.finishs[i]()
+	}
+}
+
 // setStatusCode 设置响应状态码
 func (ctx *Context) setStatusCode(code int) {
 	ctx.Writer.WriteHeader(code)
@@ -0,0 +1,207 @@
+package cc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// IDecoder 配置文件解码器，将文件内容解码为配置映射
+type IDecoder interface {
+	Decode(content []byte) (map[string]any, error)
+}
+
+// DecoderFunc 函数形式的解码器
+type DecoderFunc func(content []byte) (map[string]any, error)
+
+// Decode 实现 IDecoder 接口
+func (f DecoderFunc) Decode(content []byte) (map[string]any, error) {
+	return f(content)
+}
+
+// defaultDecoders 内置解码器，按扩展名区分
+var defaultDecoders = map[string]IDecoder{
+	".yaml": DecoderFunc(decodeYAML),
+	".yml":  DecoderFunc(decodeYAML),
+	".json": DecoderFunc(decodeJSON),
+	".toml": DecoderFunc(decodeTOML),
+}
+
+// dotenvFile 本地开发使用的环境变量文件，production 为 true 时不加载
+const dotenvFile = ".env"
+
+// dotenv 记录由 .env 文件设置的环境变量，重新加载时随文件更新或移除，进程原有的环境变量不会被覆盖
+var dotenv = struct {
+	sync.Mutex
+	keys map[string]bool
+}{keys: make(map[string]bool)}
+
+// RegisterDecoder 注册配置文件解码器，如 RegisterDecoder(".hcl", decoder)，可覆盖内置的 yaml、json 及 toml 解码器
+func (config *AppConfig) RegisterDecoder(ext string, decoder IDecoder) {
+	if config.decoders == nil {
+		config.decoders = make(map[string]IDecoder)
+	}
+	config.decoders[normalizeExt(ext)] = decoder
+}
+
+// decoder 依据扩展名获取解码器，无扩展名时按 YAML 解码
+func (config *AppConfig) decoder(path string) (IDecoder, error) {
+	ext := normalizeExt(filepath.Ext(path))
+	if ext == "" {
+		ext = ".yaml"
+	}
+	if decoder, ok := config.decoders[ext]; ok {
+		return decoder, nil
+	}
+	if decoder, ok := defaultDecoders[ext]; ok {
+		return decoder, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported config format %q", path, ext)
+}
+
+// extensions 支持的扩展名，yaml 优先，其余按字母序
+func (config *AppConfig) extensions() []string {
+	exts := []string{".yaml", ".yml"}
+	seen := map[string]bool{".yaml": true, ".yml": true}
+	for _, ext := range append(sortedKeys(defaultDecoders), sortedKeys(config.decoders)...) {
+		if !seen[ext] {
+			seen[ext] = true
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// normalizeExt 统一为小写且带点的扩展名
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// decodeYAML 解码 YAML
+func decodeYAML(content []byte) (map[string]any, error) {
+	var raw any
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	return toMapping(normalizeYAML(raw))
+}
+
+// decodeJSON 解码 JSON
+func decodeJSON(content []byte) (map[string]any, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return make(map[string]any), nil
+	}
+	var raw any
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	return toMapping(raw)
+}
+
+// decodeTOML 解码 TOML
+func decodeTOML(content []byte) (map[string]any, error) {
+	raw := make(map[string]any)
+	if err := toml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// toMapping 校验顶层为映射
+func toMapping(raw any) (map[string]any, error) {
+	if raw == nil {
+		return make(map[string]any), nil
+	}
+	layer, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("top level must be a mapping")
+	}
+	return layer, nil
+}
+
+// loadDotenv 加载 .env 文件中的环境变量，已存在的环境变量优先；文件不存在时忽略
+func loadDotenv(path string) error {
+	var values map[string]string
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if values, err = parseDotenv(content); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		LogInfof("Loading environment from %s", path)
+	case !os.IsNotExist(err):
+		return err
+	}
+	applyDotenv(values)
+	return nil
+}
+
+// applyDotenv 设置 .env 中的环境变量，并移除此前由 .env 设置但已不存在的变量
+func applyDotenv(values map[string]string) {
+	dotenv.Lock()
+	defer dotenv.Unlock()
+	for key := range dotenv.keys {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+			delete(dotenv.keys, key)
+		}
+	}
+	for _, key := range sortedKeys(values) {
+		if _, ok := os.LookupEnv(key); ok && !dotenv.keys[key] {
+			continue
+		}
+		os.Setenv(key, values[key])
+		dotenv.keys[key] = true
+	}
+}
+
+// parseDotenv 解析 KEY=VALUE 格式，支持 # 注释、export 前缀、单引号原样及双引号转义
+func parseDotenv(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value", line)
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return nil, fmt.Errorf("line %d: invalid quoted value", line)
+			}
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,94 @@
+package cc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthCheck 健康检查项
+type healthCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+// healthReport 健康检查结果
+type healthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// AddHealthCheck 添加就绪检查项，任一检查失败时 /readyz 返回 503
+func (engine *Engine) AddHealthCheck(name string, check func(ctx context.Context) error) {
+	engine.healthMu.Lock()
+	defer engine.healthMu.Unlock()
+	engine.healthChecks = append(engine.healthChecks, healthCheck{name: name, check: check})
+}
+
+// Draining 服务是否正在关闭，关闭开始后就绪检查立即失败
+func (engine *Engine) Draining() bool {
+	return engine.draining.Load()
+}
+
+// serveHealth 处理健康检查请求，绕过拦截器，返回是否已处理
+func (engine *Engine) serveHealth(w http.ResponseWriter, r *http.Request) bool {
+	config := engine.Config().Health
+	if !config.Enabled || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	var report healthReport
+	switch r.URL.Path {
+	case config.LivenessPath:
+		report = healthReport{Status: "ok"}
+	case config.ReadinessPath:
+		report = engine.readiness(r.Context())
+	default:
+		return false
+	}
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(report)
+	}
+	return true
+}
+
+// readiness 并发执行就绪检查
+func (engine *Engine) readiness(ctx context.Context) healthReport {
+	if engine.Draining() {
+		return healthReport{Status: "draining"}
+	}
+	engine.healthMu.RLock()
+	checks := engine.healthChecks
+	engine.healthMu.RUnlock()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(engine.Config().Health.Timeout)*time.Second)
+	defer cancel()
+	report := healthReport{Status: "ok", Checks: make(map[string]string, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, item := range checks {
+		wg.Add(1)
+		go func(item healthCheck) {
+			defer wg.Done()
+			result := "ok"
+			if err := item.check(ctx); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[item.name] = result
+			if result != "ok" {
+				report.Status = "unavailable"
+			}
+		}(item)
+	}
+	wg.Wait()
+	return report
+}
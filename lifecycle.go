@@ -0,0 +1,248 @@
+package cc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// OnStart 添加服务启动前执行的钩子，按添加顺序执行，任一失败则放弃启动
+func (engine *Engine) OnStart(hook func(ctx context.Context) error) {
+	engine.startHooks = append(engine.startHooks, hook)
+}
+
+// OnShutdown 添加服务关闭时执行的钩子，在停止接收请求后按添加顺序执行，
+// 用于刷新队列、关闭缓存、从服务发现中注销等
+func (engine *Engine) OnShutdown(hook func(ctx context.Context) error) {
+	engine.shutdownHooks = append(engine.shutdownHooks, hook)
+}
+
+// Shutdown 优雅关闭服务，可多次调用，仅首次生效
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	engine.shutdownOnce.Do(func() {
+		engine.shutdownErr = engine.shutdown(ctx)
+		close(engine.stopped)
+	})
+	return engine.shutdownErr
+}
+
+// runHooks 依次执行钩子，每个钩子受 hook-timeout 限制；
+// 启动钩子遇错即止，关闭钩子会全部执行并汇总错误，整体超时后跳过剩余钩子
+func (engine *Engine) runHooks(ctx context.Context, stage string, hooks []func(context.Context) error) error {
+	var errs []error
+	for i, hook := range hooks {
+		err := ctx.Err()
+		if err == nil {
+			err = engine.runHook(ctx, hook)
+		}
+		if err != nil {
+			err = fmt.Errorf("%s hook #%d: %w", stage, i+1, err)
+			if stage == "start" {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHook 执行单个钩子，超时后不再等待其返回
+func (engine *Engine) runHook(ctx context.Context, hook func(context.Context) error) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(engine.Config().HookTimeout)*time.Second)
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		result <- hook(ctx)
+	}()
+	select {
+	case err = <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start 初始化并在配置的监听地址上启动服务，不阻塞；ctx 结束时优雅关闭服务。
+// 启动后可通过 Addr 获取监听地址，通过 Wait 等待服务结束
+func (engine *Engine) Start(ctx context.Context, options ...any) error {
+	if err := engine.boot(ctx, options...); err != nil {
+		return err
+	}
+	listeners, err := engine.listen()
+	if err != nil {
+		return err
+	}
+	redirect, err := engine.newRedirectServer(listeners)
+	if err != nil {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+		return err
+	}
+	for _, listener := range listeners {
+		engine.addListener(listener)
+		go func(listener *boundListener) {
+			var err error
+			if listener.redirect {
+				LogInfo("Redirecting HTTP to HTTPS at", listener.Addr())
+				if err = redirect.Serve(listener); errors.Is(err, http.ErrServerClosed) {
+					err = nil
+				}
+			} else {
+				err = engine.serve(listener, listener.secure)
+			}
+			if err != nil {
+				engine.mu.Lock()
+				engine.serveErr = errors.Join(engine.serveErr, err)
+				engine.mu.Unlock()
+				engine.shutdownWithTimeout()
+			}
+		}(listener)
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			engine.shutdownWithTimeout()
+		case <-engine.stopped:
+		}
+	}()
+	return nil
+}
+
+// Serve 初始化并在指定监听器上提供服务，阻塞至服务关闭；优雅关闭时返回关闭过程中的错误
+func (engine *Engine) Serve(listener net.Listener, options ...any) error {
+	if err := engine.boot(context.Background(), options...); err != nil {
+		listener.Close()
+		return err
+	}
+	engine.addListener(&boundListener{Listener: listener, secure: engine.hasCert()})
+	if err := engine.serve(listener, engine.hasCert()); err != nil {
+		return err
+	}
+	<-engine.stopped
+	return engine.shutdownErr
+}
+
+// Addr 获取首个服务监听地址 (不含重定向监听器)，端口配置为 0 时可据此获取实际端口，未启动时返回 nil
+func (engine *Engine) Addr() net.Addr {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	for _, listener := range engine.listeners {
+		if !listener.redirect {
+			return listener.Addr()
+		}
+	}
+	return nil
+}
+
+// Wait 阻塞至服务关闭，返回服务及关闭过程中的错误
+func (engine *Engine) Wait() error {
+	<-engine.stopped
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	return errors.Join(engine.serveErr, engine.shutdownErr)
+}
+
+// prepare 解析运行参数及配置并初始化，仅执行一次
+func (engine *Engine) prepare(ctx context.Context, options ...any) error {
+	engine.prepareOnce.Do(func() {
+		engine.parseOptions(options...)
+		if err := engine.parseConfig(); err != nil {
+			engine.prepareErr = fmt.Errorf("parse config: %w", err)
+			return
+		}
+		if err := engine.initConfig(ctx); err != nil {
+			engine.prepareErr = fmt.Errorf("init config: %w", err)
+			return
+		}
+		server, err := engine.newServer()
+		if err != nil {
+			engine.prepareErr = fmt.Errorf("init server: %w", err)
+			return
+		}
+		engine.mu.Lock()
+		defer engine.mu.Unlock()
+		engine.server = server
+		if engine.draining.Load() {
+			engine.server.Close()
+		}
+	})
+	return engine.prepareErr
+}
+
+// boot 初始化并执行启动钩子，仅执行一次
+func (engine *Engine) boot(ctx context.Context, options ...any) error {
+	if err := engine.prepare(ctx, options...); err != nil {
+		return err
+	}
+	engine.bootOnce.Do(func() {
+		if engine.bootErr = engine.runHooks(context.Background(), "start", engine.startHooks); engine.bootErr == nil {
+			engine.bootErr = engine.watchConfig()
+		}
+		if engine.bootErr == nil {
+			engine.logPoolStats()
+		}
+	})
+	return engine.bootErr
+}
+
+// serve 在监听器上提供服务 http/https，服务关闭时返回 nil
+func (engine *Engine) serve(listener net.Listener, secure bool) error {
+	engine.mu.Lock()
+	server := engine.server
+	engine.mu.Unlock()
+	LogInfo("Server is ready to handle requests at", listener.Addr())
+	var err error
+	if secure {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// newRedirectServer 存在重定向监听器时构造 HTTP→HTTPS 重定向服务
+func (engine *Engine) newRedirectServer(listeners []*boundListener) (*http.Server, error) {
+	for _, listener := range listeners {
+		if !listener.redirect {
+			continue
+		}
+		port, ok := httpsPort(listeners)
+		if !ok {
+			return nil, fmt.Errorf("listener %s: redirect requires a tls listener", listener.Addr())
+		}
+		server := engine.baseServer(redirectHandler(port))
+		engine.mu.Lock()
+		engine.redirectServer = server
+		engine.mu.Unlock()
+		return server, nil
+	}
+	return nil, nil
+}
+
+// addListener 记录监听器
+func (engine *Engine) addListener(listener *boundListener) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	engine.listeners = append(engine.listeners, listener)
+}
+
+// shutdownWithTimeout 以配置的超时时间关闭服务
+func (engine *Engine) shutdownWithTimeout() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(engine.Config().ShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := engine.Shutdown(ctx); err != nil {
+		LogErrf("Could not gracefully shutdown the server: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package cc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 监听地址前缀
+const (
+	unixScheme    = "unix://"
+	systemdScheme = "systemd"
+)
+
+// ListenerConfig 监听配置
+//
+//	address: 127.0.0.1:8080          # TCP，可指定绑定地址
+//	address: unix:///run/cc.sock     # Unix 套接字，mode 为八进制权限，如 "0660"
+//	address: systemd                 # systemd 套接字激活传递的全部套接字
+//	address: systemd:web             # 依据 LISTEN_FDNAMES 选择套接字
+type ListenerConfig struct {
+	Address  string `yaml:"address"`
+	Mode     string `yaml:"mode"`
+	TLS      bool   `yaml:"tls"`
+	Redirect bool   `yaml:"redirect"`
+}
+
+// boundListener 已绑定的监听器
+type boundListener struct {
+	net.Listener
+	secure   bool
+	redirect bool
+}
+
+// Addrs 按配置顺序获取全部监听地址，包括重定向监听器
+func (engine *Engine) Addrs() []net.Addr {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	addrs := make([]net.Addr, 0, len(engine.listeners))
+	for _, listener := range engine.listeners {
+		addrs = append(addrs, listener.Addr())
+	}
+	return addrs
+}
+
+// listen 依据配置绑定全部监听器，未配置时监听 port
+func (engine *Engine) listen() ([]*boundListener, error) {
+	configs := engine.config.Listeners
+	if len(configs) == 0 {
+		configs = []ListenerConfig{{Address: fmt.Sprintf(":%d", engine.config.Port), TLS: engine.hasCert()}}
+	}
+	var bound []*boundListener
+	closeAll := func() {
+		for _, listener := range bound {
+			listener.Close()
+		}
+	}
+	for _, config := range configs {
+		if config.TLS && config.Redirect {
+			closeAll()
+			return nil, fmt.Errorf("listener %s: tls and redirect are mutually exclusive", config.Address)
+		}
+		if config.TLS && !engine.hasCert() {
+			closeAll()
+			return nil, fmt.Errorf("listener %s: tls requires a certificate", config.Address)
+		}
+		listeners, err := openListener(config)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("listen %s: %w", config.Address, err)
+		}
+		for _, listener := range listeners {
+			bound = append(bound, &boundListener{Listener: listener, secure: config.TLS, redirect: config.Redirect})
+		}
+	}
+	return bound, nil
+}
+
+// openListener 绑定单个监听配置
+func openListener(config ListenerConfig) ([]net.Listener, error) {
+	switch {
+	case strings.HasPrefix(config.Address, unixScheme):
+		path := strings.TrimPrefix(config.Address, unixScheme)
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if config.Mode != "" {
+			mode, err := strconv.ParseUint(config.Mode, 8, 32)
+			if err != nil {
+				listener.Close()
+				return nil, fmt.Errorf("invalid mode %q", config.Mode)
+			}
+			if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+				listener.Close()
+				return nil, err
+			}
+		}
+		return []net.Listener{listener}, nil
+	case config.Address == systemdScheme || strings.HasPrefix(config.Address, systemdScheme+":"):
+		all, err := systemdListeners()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(config.Address, systemdScheme), ":")
+		if len(all[name]) == 0 {
+			return nil, fmt.Errorf("no systemd socket named %q", name)
+		}
+		return all[name], nil
+	default:
+		listener, err := net.Listen("tcp", config.Address)
+		if err != nil {
+			return nil, err
+		}
+		return []net.Listener{listener}, nil
+	}
+}
+
+// redirectHandler 将 HTTP 请求重定向到 HTTPS，port 为 HTTPS 端口
+func redirectHandler(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
+
+// httpsPort 获取首个 HTTPS 监听器的端口
+func httpsPort(listeners []*boundListener) (string, bool) {
+	for _, listener := range listeners {
+		if !listener.secure {
+			continue
+		}
+		if addr, ok := listener.Addr().(*net.TCPAddr); ok {
+			return strconv.Itoa(addr.Port), true
+		}
+		return "", true
+	}
+	return "", false
+}
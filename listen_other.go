@@ -0,0 +1,13 @@
+//go:build !unix
+
+package cc
+
+import (
+	"errors"
+	"net"
+)
+
+// systemdListeners 非 Unix 系统不支持 systemd 套接字激活
+func systemdListeners() (map[string][]net.Listener, error) {
+	return nil, errors.New("systemd socket activation is not supported on this platform")
+}
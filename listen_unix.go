@@ -0,0 +1,50 @@
+//go:build unix
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// listenFdsStart systemd 传递的首个文件描述符
+const listenFdsStart = 3
+
+// systemdListeners systemd 套接字激活传递的监听器，仅解析一次
+var systemdListeners = sync.OnceValues(func() (map[string][]net.Listener, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, errors.New("no sockets passed by systemd")
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, errors.New("no sockets passed by systemd")
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	listeners := make(map[string][]net.Listener)
+	for i := 0; i < n; i++ {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("systemd socket %s: %w", name, err)
+		}
+		listeners[name] = append(listeners[name], listener)
+		listeners[""] = append(listeners[""], listener)
+	}
+	return listeners, nil
+})
@@ -1,22 +1,172 @@
 package cc
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/cquestor/cc/logger"
 )
 
-var (
-	infoLogger = logger.NewLogger(os.Stderr, nil, "[INFO]", logger.ColorBlue, logger.StyleBold)
-	warnLogger = logger.NewLogger(os.Stderr, nil, "[WARN]", logger.ColorYellow, logger.StyleBold)
-	errLogger  = logger.NewLogger(os.Stderr, nil, "[ERROR]", logger.ColorRed, logger.StyleBold)
-)
+var defaultLogger atomic.Pointer[logger.Logger]
 
-var (
-	LogInfo  = infoLogger.Println
-	LogInfof = infoLogger.Printf
-	LogWarn  = warnLogger.Println
-	LogWarnf = warnLogger.Printf
-	LogErr   = errLogger.Println
-	LogErrf  = errLogger.Printf
-)
+func init() {
+	defaultLogger.Store(logger.New(logger.LevelInfo, logger.NewConsoleOutput(os.Stderr)))
+}
+
+// Log 获取框架日志记录器
+func Log() *logger.Logger {
+	return defaultLogger.Load()
+}
+
+// requestLogs 处理中的请求，协程 ID → *Context
+var requestLogs sync.Map
+
+// requestLogCount 处理中的请求数，为 0 时跳过协程 ID 查找
+var requestLogCount atomic.Int64
+
+// bindRequestLog 将当前协程关联到请求，关联期间不传入上下文的 LogInfo 等同样附加请求日志字段，
+// 返回解除关联的函数；处理器中另起的协程不会关联，需使用 ctx.Logger() 或 LogInfoCtx 等
+func bindRequestLog(ctx *Context) func() {
+	id := goroutineID()
+	requestLogs.Store(id, ctx)
+	requestLogCount.Add(1)
+	return func() {
+		requestLogs.Delete(id)
+		requestLogCount.Add(-1)
+	}
+}
+
+// requestLog 获取日志记录器，当前协程正在处理请求时附加请求上下文的日志字段
+func requestLog() *logger.Logger {
+	if requestLogCount.Load() == 0 {
+		return Log()
+	}
+	if ctx, ok := requestLogs.Load(goroutineID()); ok {
+		return ctx.(*Context).Logger()
+	}
+	return Log()
+}
+
+// goroutineID 解析当前协程 ID，格式为 "goroutine 18 [running]:"
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i > 0 {
+		stack = stack[:i]
+	}
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}
+
+// SetLogger 替换框架日志记录器
+func SetLogger(l *logger.Logger) {
+	defaultLogger.Store(l)
+}
+
+// LogDebug 输出 DEBUG 日志
+func LogDebug(v ...any) {
+	requestLog().Debug(sprintln(v...))
+}
+
+// LogDebugf 格式化输出 DEBUG 日志
+func LogDebugf(format string, v ...any) {
+	requestLog().Debug(sprintf(format, v...))
+}
+
+// LogInfo 输出 INFO 日志
+func LogInfo(v ...any) {
+	requestLog().Info(sprintln(v...))
+}
+
+// LogInfof 格式化输出 INFO 日志
+func LogInfof(format string, v ...any) {
+	requestLog().Info(sprintf(format, v...))
+}
+
+// LogWarn 输出 WARN 日志
+func LogWarn(v ...any) {
+	requestLog().Warn(sprintln(v...))
+}
+
+// LogWarnf 格式化输出 WARN 日志
+func LogWarnf(format string, v ...any) {
+	requestLog().Warn(sprintf(format, v...))
+}
+
+// LogErr 输出 ERROR 日志
+func LogErr(v ...any) {
+	requestLog().Error(sprintln(v...))
+}
+
+// LogErrf 格式化输出 ERROR 日志
+func LogErrf(format string, v ...any) {
+	requestLog().Error(sprintf(format, v...))
+}
+
+// LogFatal 输出 FATAL 日志并退出程序
+func LogFatal(v ...any) {
+	requestLog().Fatal(sprintln(v...))
+}
+
+// LogFatalf 格式化输出 FATAL 日志并退出程序
+func LogFatalf(format string, v ...any) {
+	requestLog().Fatal(sprintf(format, v...))
+}
+
+// LogDebugCtx 输出 DEBUG 日志，附加 ctx 携带的日志字段，如请求 ID 中间件设置的 request_id
+func LogDebugCtx(ctx context.Context, v ...any) {
+	Log().WithContext(ctx).Debug(sprintln(v...))
+}
+
+// LogDebugfCtx 格式化输出 DEBUG 日志，附加 ctx 携带的日志字段
+func LogDebugfCtx(ctx context.Context, format string, v ...any) {
+	Log().WithContext(ctx).Debug(sprintf(format, v...))
+}
+
+// LogInfoCtx 输出 INFO 日志，附加 ctx 携带的日志字段，处理请求时传入 ctx.Req.Context()
+func LogInfoCtx(ctx context.Context, v ...any) {
+	Log().WithContext(ctx).Info(sprintln(v...))
+}
+
+// LogInfofCtx 格式化输出 INFO 日志，附加 ctx 携带的日志字段
+func LogInfofCtx(ctx context.Context, format string, v ...any) {
+	Log().WithContext(ctx).Info(sprintf(format, v...))
+}
+
+// LogWarnCtx 输出 WARN 日志，附加 ctx 携带的日志字段
+func LogWarnCtx(ctx context.Context, v ...any) {
+	Log().WithContext(ctx).Warn(sprintln(v...))
+}
+
+// LogWarnfCtx 格式化输出 WARN 日志，附加 ctx 携带的日志字段
+func LogWarnfCtx(ctx context.Context, format string, v ...any) {
+	Log().WithContext(ctx).Warn(sprintf(format, v...))
+}
+
+// LogErrCtx 输出 ERROR 日志，附加 ctx 携带的日志字段
+func LogErrCtx(ctx context.Context, v ...any) {
+	Log().WithContext(ctx).Error(sprintln(v...))
+}
+
+// LogErrfCtx 格式化输出 ERROR 日志，附加 ctx 携带的日志字段
+func LogErrfCtx(ctx context.Context, format string, v ...any) {
+	Log().WithContext(ctx).Error(sprintf(format, v...))
+}
+
+// sprintln 与 fmt.Sprintln 一致，去除结尾换行
+func sprintln(v ...any) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
+
+// sprintf 与 fmt.Sprintf 一致，去除结尾换行
+func sprintf(format string, v ...any) string {
+	return strings.TrimRight(fmt.Sprintf(format, v...), " \n")
+}
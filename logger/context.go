@@ -0,0 +1,34 @@
+package logger
+
+import "context"
+
+// contextKey 上下文字段键
+type contextKey struct{}
+
+// NewContext 构造携带日志字段的上下文，字段会附加到父上下文已有字段之后
+func NewContext(parent context.Context, kv ...any) context.Context {
+	parentFields := FieldsFromContext(parent)
+	fields := make([]Field, 0, len(parentFields)+len(kv)/2)
+	fields = append(fields, parentFields...)
+	return context.WithValue(parent, contextKey{}, appendFields(fields, kv))
+}
+
+// FieldsFromContext 获取上下文携带的日志字段
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextKey{}).([]Field)
+	return fields
+}
+
+// WithContext 派生携带上下文字段的日志记录器
+func (logger *Logger) WithContext(ctx context.Context) *Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	merged := make([]Field, 0, len(logger.fields)+len(fields))
+	merged = append(merged, logger.fields...)
+	return &Logger{core: logger.core, fields: append(merged, fields...)}
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IEncoder 日志编码器接口
+type IEncoder interface {
+	Encode(buf *bytes.Buffer, entry *Entry) error
+}
+
+// TextEncoder 文本编码器
+type TextEncoder struct {
+	Color      bool   // 是否输出颜色，关闭时同时去除消息中的控制序列
+	TimeFormat string // 时间格式，默认 2006-01-02 15:04:05
+}
+
+// JSONEncoder JSON 编码器
+type JSONEncoder struct {
+	TimeFormat string // 时间格式，默认 RFC3339Nano
+}
+
+// levelColors 各级别颜色
+var levelColors = map[Level]TypeColor{
+	LevelDebug: ColorCyan,
+	LevelInfo:  ColorBlue,
+	LevelWarn:  ColorYellow,
+	LevelError: ColorRed,
+	LevelFatal: ColorMagenta,
+}
+
+// Encode 实现 IEncoder 接口
+func (encoder *TextEncoder) Encode(buf *bytes.Buffer, entry *Entry) error {
+	timeFormat := encoder.TimeFormat
+	if timeFormat == "" {
+		timeFormat = "2006-01-02 15:04:05"
+	}
+	var line strings.Builder
+	line.WriteString("[" + entry.Level.String() + "] ")
+	line.WriteString(entry.Time.Format(timeFormat))
+	line.WriteString(" ")
+	line.WriteString(entry.Message)
+	for _, field := range entry.Fields {
+		line.WriteString(" ")
+		line.WriteString(field.Key)
+		line.WriteString("=")
+		line.WriteString(textValue(field.Value))
+	}
+	if encoder.Color {
+		buf.WriteString(setStyle(levelColors[entry.Level], StyleBold, line.String()))
+	} else {
+		buf.WriteString(StripStyle(line.String()))
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+// Encode 实现 IEncoder 接口
+func (encoder *JSONEncoder) Encode(buf *bytes.Buffer, entry *Entry) error {
+	timeFormat := encoder.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+	buf.WriteString(`{"time":`)
+	buf.WriteString(strconv.Quote(entry.Time.Format(timeFormat)))
+	buf.WriteString(`,"level":`)
+	buf.WriteString(strconv.Quote(entry.Level.String()))
+	buf.WriteString(`,"msg":`)
+	if err := writeJSON(buf, StripStyle(entry.Message)); err != nil {
+		return err
+	}
+	for _, field := range entry.Fields {
+		buf.WriteString(",")
+		if err := writeJSON(buf, field.Key); err != nil {
+			return err
+		}
+		buf.WriteString(":")
+		if err := writeJSON(buf, jsonValue(field.Value)); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// writeJSON 写入 JSON 值
+func writeJSON(buf *bytes.Buffer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, err = json.Marshal(fmt.Sprint(v))
+		if err != nil {
+			return err
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// jsonValue 转换 JSON 不支持直接编码的字段值
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+// textValue 格式化文本字段值，包含空白或引号时加引号
+func textValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -8,8 +8,6 @@ import (
 	"time"
 )
 
-// TODO LogFatal LogFatalf os.Exit(1)
-
 const (
 	ColorRed TypeColor = iota + 31
 	ColorGreen
@@ -96,7 +94,7 @@ func (logger *CLogger) Write(v []byte) {
 		logger.ConsoleOut.Write(v)
 	}
 	if logger.FileOut != nil {
-		logger.FileOut.Write(v)
+		logger.FileOut.Write([]byte(StripStyle(string(v))))
 	}
 }
 
@@ -1,7 +1,11 @@
 package logger_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,3 +32,113 @@ func TestLogger(t *testing.T) {
 		}
 	})
 }
+
+func TestStructLogger(t *testing.T) {
+	var text, data bytes.Buffer
+	log := logger.New(logger.LevelInfo, logger.NewTextOutput(&text), logger.NewJSONOutput(&data))
+	t.Run("level", func(t *testing.T) {
+		log.Debug("hidden")
+		if text.Len() != 0 || data.Len() != 0 {
+			t.Fatalf("debug entry should be filtered: %s\n", text.String())
+		}
+		log.SetLevel(logger.LevelDebug)
+		defer log.SetLevel(logger.LevelInfo)
+		log.Debug("shown")
+		if !strings.Contains(text.String(), "[DEBUG]") {
+			t.Fatalf("debug entry missing: %s\n", text.String())
+		}
+		text.Reset()
+		data.Reset()
+	})
+	t.Run("fields", func(t *testing.T) {
+		log.With("user", "admin").Warn("login failed", "attempts", 3, "reason", "bad password")
+		if line := text.String(); !strings.Contains(line, "[WARN]") || !strings.Contains(line, `login failed user=admin attempts=3 reason="bad password"`) {
+			t.Fatalf("text entry error: %s\n", line)
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(data.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry["level"] != "WARN" || entry["msg"] != "login failed" || entry["user"] != "admin" || entry["attempts"] != float64(3) {
+			t.Fatalf("json entry error: %+v\n", entry)
+		}
+		text.Reset()
+		data.Reset()
+	})
+	t.Run("slog", func(t *testing.T) {
+		log.Slog().With("module", "orm").WithGroup("query").Error("slow query", "cost", 200)
+		if line := text.String(); !strings.Contains(line, "[ERROR]") || !strings.Contains(line, "module=orm query.cost=200") {
+			t.Fatalf("slog entry error: %s\n", line)
+		}
+		text.Reset()
+		data.Reset()
+		log.Slog().Debug("hidden")
+		if text.Len() != 0 {
+			t.Fatalf("slog should respect level: %s\n", text.String())
+		}
+	})
+	t.Run("level parse", func(t *testing.T) {
+		if level, err := logger.ParseLevel("warning"); err != nil || level != logger.LevelWarn {
+			t.Fatalf("parse level error: %v %v\n", level, err)
+		}
+		if _, err := logger.ParseLevel("verbose"); err == nil {
+			t.Fatalf("unknown level should be rejected")
+		}
+	})
+}
+
+func TestRotateWriter(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	t.Run("append", func(t *testing.T) {
+		if err := os.WriteFile(filename, []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := logger.GetFileWriter(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write([]byte("new\n")); err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+		if content, _ := os.ReadFile(filename); string(content) != "old\nnew\n" {
+			t.Fatalf("append error: %q\n", content)
+		}
+	})
+	t.Run("rotate", func(t *testing.T) {
+		writer := logger.NewRotateWriter(filename)
+		writer.MaxSize = 16
+		writer.MaxBackups = 2
+		writer.Compress = true
+		log := logger.New(logger.LevelInfo, logger.NewTextOutput(writer))
+		for i := 0; i < 5; i++ {
+			log.Info(logger.Style(logger.ColorRed, logger.StyleBold, "rotate"), "index", i)
+			time.Sleep(2 * time.Millisecond)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+		if len(backups) != 2 {
+			t.Fatalf("backup count error: %v\n", backups)
+		}
+		content, _ := os.ReadFile(filename)
+		if !strings.Contains(string(content), "rotate index=4") || strings.Contains(string(content), "\033[") {
+			t.Fatalf("current file error: %q\n", content)
+		}
+	})
+	t.Run("reopen", func(t *testing.T) {
+		writer := logger.NewRotateWriter(filename)
+		writer.Write([]byte("before\n"))
+		os.Rename(filename, filename+".1")
+		if err := writer.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte("after\n"))
+		writer.Close()
+		if content, _ := os.ReadFile(filename); string(content) != "after\n" {
+			t.Fatalf("reopen error: %q\n", content)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat 历史文件时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateWriter 按大小及时间轮转的文件输出
+type RotateWriter struct {
+	Filename   string        // 文件路径
+	MaxSize    int64         // 单个文件最大字节数，0 表示不按大小轮转
+	Interval   time.Duration // 按时间轮转的间隔，0 表示不按时间轮转
+	MaxBackups int           // 保留的历史文件数，0 表示不限制
+	MaxAge     time.Duration // 历史文件最长保留时间，0 表示不限制
+	Compress   bool          // 是否使用 gzip 压缩历史文件
+	lock       sync.Mutex
+	file       *os.File
+	size       int64
+	nextRotate time.Time
+	mill       sync.WaitGroup
+	millLock   sync.Mutex
+}
+
+// NewRotateWriter 构造轮转文件输出
+func NewRotateWriter(filename string) *RotateWriter {
+	return &RotateWriter{Filename: filename}
+}
+
+// Write 实现 io.Writer 接口
+func (writer *RotateWriter) Write(p []byte) (int, error) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.file == nil {
+		if err := writer.open(); err != nil {
+			return 0, err
+		}
+	}
+	if writer.shouldRotate(int64(len(p))) {
+		if err := writer.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := writer.file.Write(p)
+	writer.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即轮转
+func (writer *RotateWriter) Rotate() error {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	return writer.rotate()
+}
+
+// Reopen 重新打开文件，用于配合 logrotate 等外部工具
+func (writer *RotateWriter) Reopen() error {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if err := writer.close(); err != nil {
+		return err
+	}
+	return writer.open()
+}
+
+// NotifyReopen 收到信号时重新打开文件，默认监听 SIGHUP，返回取消函数；
+// 取消函数返回时不会再发生重新打开，可安全关闭文件
+func (writer *RotateWriter) NotifyReopen(sigs ...os.Signal) func() {
+	if len(sigs) < 1 {
+		sigs = append(sigs, syscall.SIGHUP)
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-ch:
+				if err := writer.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "logger: reopen %s error: %v\n", writer.Filename, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+		<-exited
+	}
+}
+
+// Close 关闭文件，并等待历史文件处理完成
+func (writer *RotateWriter) Close() error {
+	writer.lock.Lock()
+	err := writer.close()
+	writer.lock.Unlock()
+	writer.mill.Wait()
+	return err
+}
+
+// shouldRotate 判断写入前是否需要轮转
+func (writer *RotateWriter) shouldRotate(n int64) bool {
+	if writer.MaxSize > 0 && writer.size > 0 && writer.size+n > writer.MaxSize {
+		return true
+	}
+	return writer.Interval > 0 && !time.Now().Before(writer.nextRotate)
+}
+
+// open 打开文件
+func (writer *RotateWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(writer.Filename), 0755); err != nil {
+		return err
+	}
+	file, err := GetFileWriter(writer.Filename)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	writer.file = file
+	writer.size = stat.Size()
+	if writer.Interval > 0 {
+		writer.nextRotate = time.Now().Truncate(writer.Interval).Add(writer.Interval)
+	}
+	return nil
+}
+
+// close 关闭文件
+func (writer *RotateWriter) close() error {
+	if writer.file == nil {
+		return nil
+	}
+	err := writer.file.Close()
+	writer.file = nil
+	return err
+}
+
+// rotate 将当前文件重命名为历史文件并打开新文件
+func (writer *RotateWriter) rotate() error {
+	if err := writer.close(); err != nil {
+		return err
+	}
+	if fileExists(writer.Filename) {
+		if err := os.Rename(writer.Filename, writer.backupName(time.Now())); err != nil {
+			return err
+		}
+	}
+	if err := writer.open(); err != nil {
+		return err
+	}
+	writer.mill.Add(1)
+	go func() {
+		defer writer.mill.Done()
+		writer.millLock.Lock()
+		defer writer.millLock.Unlock()
+		if err := writer.cleanBackups(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: clean backups of %s error: %v\n", writer.Filename, err)
+		}
+	}()
+	return nil
+}
+
+// backupName 生成历史文件名，如 app-2006-01-02T15-04-05.000.log
+func (writer *RotateWriter) backupName(t time.Time) string {
+	dir, prefix, ext := writer.nameParts()
+	return filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+}
+
+// nameParts 拆分文件名
+func (writer *RotateWriter) nameParts() (string, string, string) {
+	dir := filepath.Dir(writer.Filename)
+	base := filepath.Base(writer.Filename)
+	ext := filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// backupFile 历史文件
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups 获取历史文件，按时间由新到旧排序
+func (writer *RotateWriter) backups() ([]backupFile, error) {
+	dir, prefix, ext := writer.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]backupFile, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name[len(prefix):], ".gz"), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, name), time: t})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].time.After(files[j].time)
+	})
+	return files, nil
+}
+
+// cleanBackups 删除超出数量或过期的历史文件，并压缩剩余文件
+func (writer *RotateWriter) cleanBackups() error {
+	files, err := writer.backups()
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-writer.MaxAge)
+	for i, file := range files {
+		if (writer.MaxBackups > 0 && i >= writer.MaxBackups) || (writer.MaxAge > 0 && file.time.Before(cutoff)) {
+			if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if writer.Compress && !strings.HasSuffix(file.path, ".gz") {
+			if err := compressFile(file.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile 使用 gzip 压缩文件并删除原文件
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
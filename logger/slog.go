@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler 将 log/slog 日志写入 Logger 的适配器
+type SlogHandler struct {
+	logger *Logger
+	group  string
+}
+
+// NewSlogHandler 构造 slog.Handler
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+// Slog 获取共用该日志管道的 *slog.Logger
+func (logger *Logger) Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(logger))
+}
+
+// Enabled 实现 slog.Handler 接口
+func (handler *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return handler.logger.Enabled(Level(level))
+}
+
+// Handle 实现 slog.Handler 接口
+func (handler *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	level := Level(record.Level)
+	contextFields := FieldsFromContext(ctx)
+	fields := make([]Field, 0, len(handler.logger.fields)+len(contextFields)+record.NumAttrs())
+	fields = append(fields, handler.logger.fields...)
+	fields = append(fields, contextFields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, handler.group, attr)
+		return true
+	})
+	handler.logger.write(&Entry{
+		Time:    record.Time,
+		Level:   level,
+		Message: record.Message,
+		Fields:  fields,
+	})
+	return nil
+}
+
+// WithAttrs 实现 slog.Handler 接口
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(handler.logger.fields)+len(attrs))
+	fields = append(fields, handler.logger.fields...)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, handler.group, attr)
+	}
+	return &SlogHandler{
+		logger: &Logger{core: handler.logger.core, fields: fields},
+		group:  handler.group,
+	}
+}
+
+// WithGroup 实现 slog.Handler 接口
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	return &SlogHandler{logger: handler.logger, group: handler.group + name + "."}
+}
+
+// appendAttr 将 slog 属性展开为字段，分组以 "." 连接
+func appendAttr(fields []Field, group string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, each := range attr.Value.Group() {
+			fields = appendAttr(fields, prefix, each)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: group + attr.Key, Value: attr.Value.Any()})
+}
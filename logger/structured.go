@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level 日志级别，取值与 log/slog 保持一致
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+	LevelFatal Level = 12
+)
+
+// Field 日志字段
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Entry 日志条目
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// Output 日志输出，每个输出拥有独立的编码器
+type Output struct {
+	Writer  io.Writer
+	Encoder IEncoder
+}
+
+// Logger 结构化日志记录器
+type Logger struct {
+	core   *core
+	fields []Field
+}
+
+// core 日志记录器共享部分，With 派生的记录器共用同一个 core
+type core struct {
+	lock    sync.Mutex
+	level   atomic.Int64
+	outputs []*Output
+}
+
+const badKey = "!BADKEY"
+
+// New 构造结构化日志记录器
+func New(level Level, outputs ...*Output) *Logger {
+	logger := &Logger{core: &core{outputs: outputs}}
+	logger.SetLevel(level)
+	return logger
+}
+
+// NewConsoleOutput 构造控制台文本输出，仅在终端中启用颜色
+func NewConsoleOutput(w io.Writer) *Output {
+	return &Output{Writer: w, Encoder: &TextEncoder{Color: IsTerminal(w)}}
+}
+
+// NewTextOutput 构造无颜色文本输出
+func NewTextOutput(w io.Writer) *Output {
+	return &Output{Writer: w, Encoder: &TextEncoder{}}
+}
+
+// NewJSONOutput 构造 JSON 输出
+func NewJSONOutput(w io.Writer) *Output {
+	return &Output{Writer: w, Encoder: &JSONEncoder{}}
+}
+
+// ParseLevel 解析日志级别
+func ParseLevel(v string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %s", v)
+}
+
+// String 日志级别名称
+func (level Level) String() string {
+	switch {
+	case level < LevelInfo:
+		return "DEBUG"
+	case level < LevelWarn:
+		return "INFO"
+	case level < LevelError:
+		return "WARN"
+	case level < LevelFatal:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+// IsTerminal 判断输出是否为终端，设置 NO_COLOR 环境变量时视为非终端
+func IsTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// SetLevel 设置最低输出级别
+func (logger *Logger) SetLevel(level Level) {
+	logger.core.level.Store(int64(level))
+}
+
+// Level 获取最低输出级别
+func (logger *Logger) Level() Level {
+	return Level(logger.core.level.Load())
+}
+
+// Enabled 判断级别是否会被输出
+func (logger *Logger) Enabled(level Level) bool {
+	return level >= logger.Level()
+}
+
+// SetOutputs 替换日志输出
+func (logger *Logger) SetOutputs(outputs ...*Output) {
+	logger.core.lock.Lock()
+	defer logger.core.lock.Unlock()
+	logger.core.outputs = outputs
+}
+
+// With 派生携带字段的日志记录器
+func (logger *Logger) With(kv ...any) *Logger {
+	if len(kv) == 0 {
+		return logger
+	}
+	fields := make([]Field, 0, len(logger.fields)+len(kv)/2)
+	fields = append(fields, logger.fields...)
+	return &Logger{core: logger.core, fields: appendFields(fields, kv)}
+}
+
+// Fields 获取记录器携带的字段
+func (logger *Logger) Fields() []Field {
+	return logger.fields
+}
+
+// Debug 输出 DEBUG 日志
+func (logger *Logger) Debug(msg string, kv ...any) {
+	logger.Log(LevelDebug, msg, kv...)
+}
+
+// Info 输出 INFO 日志
+func (logger *Logger) Info(msg string, kv ...any) {
+	logger.Log(LevelInfo, msg, kv...)
+}
+
+// Warn 输出 WARN 日志
+func (logger *Logger) Warn(msg string, kv ...any) {
+	logger.Log(LevelWarn, msg, kv...)
+}
+
+// Error 输出 ERROR 日志
+func (logger *Logger) Error(msg string, kv ...any) {
+	logger.Log(LevelError, msg, kv...)
+}
+
+// Fatal 输出 FATAL 日志并退出程序
+func (logger *Logger) Fatal(msg string, kv ...any) {
+	logger.Log(LevelFatal, msg, kv...)
+	os.Exit(1)
+}
+
+// Log 输出指定级别日志
+func (logger *Logger) Log(level Level, msg string, kv ...any) {
+	if !logger.Enabled(level) {
+		return
+	}
+	fields := make([]Field, 0, len(logger.fields)+len(kv)/2)
+	fields = append(fields, logger.fields...)
+	logger.write(&Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  appendFields(fields, kv),
+	})
+}
+
+// write 编码并写入所有输出
+func (logger *Logger) write(entry *Entry) {
+	logger.core.lock.Lock()
+	defer logger.core.lock.Unlock()
+	var buf bytes.Buffer
+	for _, output := range logger.core.outputs {
+		buf.Reset()
+		if err := output.Encoder.Encode(&buf, entry); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: encode entry error: %v\n", err)
+			continue
+		}
+		output.Writer.Write(buf.Bytes())
+	}
+}
+
+// appendFields 解析键值对字段
+func appendFields(fields []Field, kv []any) []Field {
+	for len(kv) > 0 {
+		switch key := kv[0].(type) {
+		case Field:
+			fields = append(fields, key)
+			kv = kv[1:]
+		case string:
+			if len(kv) < 2 {
+				fields = append(fields, Field{Key: badKey, Value: key})
+				kv = kv[1:]
+			} else {
+				fields = append(fields, Field{Key: key, Value: kv[1]})
+				kv = kv[2:]
+			}
+		default:
+			fields = append(fields, Field{Key: badKey, Value: key})
+			kv = kv[1:]
+		}
+	}
+	return fields
+}
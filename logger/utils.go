@@ -2,16 +2,21 @@ package logger
 
 import (
 	"fmt"
-	"io"
 	"os"
+	"regexp"
 )
 
-// GetFileWriter 获取文件流
-func GetFileWriter(filename string) (io.Writer, error) {
-	if !fileExists(filename) {
-		return os.Create(filename)
-	}
-	return os.OpenFile(filename, os.O_APPEND, 0644)
+// ansiPattern ANSI 控制序列
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*[A-Za-z]")
+
+// GetFileWriter 获取追加写入的文件流
+func GetFileWriter(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// StripStyle 去除 ANSI 颜色及样式控制序列
+func StripStyle(v string) string {
+	return ansiPattern.ReplaceAllString(v, "")
 }
 
 // fileExists 判断文件是否存在
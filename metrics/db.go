@@ -0,0 +1,35 @@
+package metrics
+
+import "database/sql"
+
+// DBStatsCollector 数据库连接池指标采集器
+type DBStatsCollector struct {
+	stats func() sql.DBStats
+}
+
+// NewDBStatsCollector 构造连接池指标采集器，stats 通常为 (*sql.DB).Stats
+func NewDBStatsCollector(stats func() sql.DBStats) *DBStatsCollector {
+	return &DBStatsCollector{stats: stats}
+}
+
+// Collect 实现 ICollector 接口
+func (collector *DBStatsCollector) Collect() []*Family {
+	stats := collector.stats()
+	gauge := func(name, help string, v float64) *Family {
+		return &Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Value: v}}}
+	}
+	counter := func(name, help string, v float64) *Family {
+		return &Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Value: v}}}
+	}
+	return []*Family{
+		gauge("cc_db_max_open_connections", "Maximum number of open connections to the database.", float64(stats.MaxOpenConnections)),
+		gauge("cc_db_open_connections", "Number of established connections both in use and idle.", float64(stats.OpenConnections)),
+		gauge("cc_db_in_use_connections", "Number of connections currently in use.", float64(stats.InUse)),
+		gauge("cc_db_idle_connections", "Number of idle connections.", float64(stats.Idle)),
+		counter("cc_db_wait_count_total", "Total number of connections waited for.", float64(stats.WaitCount)),
+		counter("cc_db_wait_duration_seconds_total", "Total time blocked waiting for a new connection in seconds.", stats.WaitDuration.Seconds()),
+		counter("cc_db_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", float64(stats.MaxIdleClosed)),
+		counter("cc_db_max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.", float64(stats.MaxIdleTimeClosed)),
+		counter("cc_db_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", float64(stats.MaxLifetimeClosed)),
+	}
+}
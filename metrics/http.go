@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// HTTPMetrics HTTP 请求指标，以路由模式而非原始路径作为标签，避免标签基数膨胀
+type HTTPMetrics struct {
+	Requests *CounterVec
+	Duration *HistogramVec
+	InFlight *Gauge
+	inFlight *GaugeVec
+}
+
+// QueryMetrics 数据库查询指标
+type QueryMetrics struct {
+	Duration *HistogramVec
+	Errors   *CounterVec
+}
+
+// NewHTTPMetrics 构造 HTTP 请求指标并注册
+func NewHTTPMetrics(registry *Registry) *HTTPMetrics {
+	inFlight := NewGaugeVec("cc_http_requests_in_flight", "Number of HTTP requests currently being served.")
+	httpMetrics := &HTTPMetrics{
+		Requests: NewCounterVec("cc_http_requests_total", "Total number of HTTP requests.", "method", "route", "status"),
+		Duration: NewHistogramVec("cc_http_request_duration_seconds", "HTTP request latency in seconds.", nil, "method", "route"),
+		InFlight: inFlight.With(),
+		inFlight: inFlight,
+	}
+	registry.Register(httpMetrics.Requests, httpMetrics.Duration, httpMetrics.inFlight)
+	return httpMetrics
+}
+
+// Observe 记录一次请求
+func (httpMetrics *HTTPMetrics) Observe(method, route string, status int, duration time.Duration) {
+	httpMetrics.Requests.With(method, route, strconv.Itoa(status)).Inc()
+	httpMetrics.Duration.With(method, route).Observe(duration.Seconds())
+}
+
+// NewQueryMetrics 构造数据库查询指标并注册
+func NewQueryMetrics(registry *Registry) *QueryMetrics {
+	queryMetrics := &QueryMetrics{
+		Duration: NewHistogramVec("cc_db_query_duration_seconds", "Database query latency in seconds.", nil, "operation"),
+		Errors:   NewCounterVec("cc_db_query_errors_total", "Total number of failed database queries.", "operation"),
+	}
+	registry.Register(queryMetrics.Duration, queryMetrics.Errors)
+	return queryMetrics
+}
+
+// Observe 记录一次查询
+func (queryMetrics *QueryMetrics) Observe(operation string, duration time.Duration, err error) {
+	queryMetrics.Duration.With(operation).Observe(duration.Seconds())
+	if err != nil {
+		queryMetrics.Errors.With(operation).Inc()
+	}
+}
@@ -0,0 +1,161 @@
+// Package metrics 提供兼容 Prometheus 文本格式的指标采集
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// 指标类型
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// DefaultBuckets 默认直方图桶，单位秒
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// ICollector 指标采集接口
+type ICollector interface {
+	Collect() []*Family
+}
+
+// Family 指标族
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample 指标样本
+type Sample struct {
+	Suffix string // 名称后缀，如 _bucket、_sum、_count
+	Labels []Label
+	Value  float64
+}
+
+// Label 标签
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Registry 指标注册表
+type Registry struct {
+	lock       sync.RWMutex
+	collectors []ICollector
+}
+
+// NewRegistry 构造指标注册表
+func NewRegistry() *Registry {
+	return &Registry{collectors: make([]ICollector, 0)}
+}
+
+// Register 注册采集器
+func (registry *Registry) Register(collectors ...ICollector) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.collectors = append(registry.collectors, collectors...)
+}
+
+// Gather 采集所有指标，按名称排序
+func (registry *Registry) Gather() []*Family {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	families := make([]*Family, 0)
+	for _, collector := range registry.collectors {
+		families = append(families, collector.Collect()...)
+	}
+	sort.SliceStable(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
+
+// WriteTo 以 Prometheus 文本格式输出所有指标
+func (registry *Registry) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, family := range registry.Gather() {
+		if family.Help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			b.WriteString(family.Name)
+			b.WriteString(sample.Suffix)
+			if len(sample.Labels) > 0 {
+				b.WriteString("{")
+				for i, label := range sample.Labels {
+					if i > 0 {
+						b.WriteString(",")
+					}
+					fmt.Fprintf(&b, "%s=\"%s\"", label.Name, escapeLabel(label.Value))
+				}
+				b.WriteString("}")
+			}
+			b.WriteString(" ")
+			b.WriteString(formatFloat(sample.Value))
+			b.WriteString("\n")
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ContentType Prometheus 文本格式的 Content-Type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// atomicFloat 原子浮点数
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+// Add 原子累加
+func (f *atomicFloat) Add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Set 原子设置
+func (f *atomicFloat) Set(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+// Load 原子读取
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// formatFloat 格式化样本值
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprint(v)
+}
+
+// escapeHelp 转义帮助信息
+func escapeHelp(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(v)
+}
+
+// escapeLabel 转义标签值
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(v)
+}
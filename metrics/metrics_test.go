@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cquestor/cc/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	t.Run("counter", func(t *testing.T) {
+		counter := metrics.NewCounterVec("jobs_total", "Total jobs.\nDone.", "queue")
+		registry.Register(counter)
+		counter.With("mail").Inc()
+		counter.With("mail").Add(2)
+		counter.With(`a"b`).Inc()
+		var out strings.Builder
+		registry.WriteTo(&out)
+		expected := "# HELP jobs_total Total jobs.\\nDone.\n# TYPE jobs_total counter\njobs_total{queue=\"a\\\"b\"} 1\njobs_total{queue=\"mail\"} 3\n"
+		if out.String() != expected {
+			t.Fatalf("counter exposition error:\n%s\n", out.String())
+		}
+	})
+	t.Run("histogram", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		histogram := metrics.NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+		registry.Register(histogram)
+		histogram.With("/user/:name").Observe(0.05)
+		histogram.With("/user/:name").Observe(0.5)
+		histogram.With("/user/:name").Observe(5)
+		var out strings.Builder
+		registry.WriteTo(&out)
+		for _, line := range []string{
+			`latency_seconds_bucket{route="/user/:name",le="0.1"} 1`,
+			`latency_seconds_bucket{route="/user/:name",le="1"} 2`,
+			`latency_seconds_bucket{route="/user/:name",le="+Inf"} 3`,
+			`latency_seconds_sum{route="/user/:name"} 5.55`,
+			`latency_seconds_count{route="/user/:name"} 3`,
+		} {
+			if !strings.Contains(out.String(), line+"\n") {
+				t.Fatalf("histogram line %q missing:\n%s\n", line, out.String())
+			}
+		}
+	})
+	t.Run("http", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		httpMetrics := metrics.NewHTTPMetrics(registry)
+		httpMetrics.InFlight.Inc()
+		httpMetrics.Observe("GET", "/user/:name", 200, 20*time.Millisecond)
+		var out strings.Builder
+		registry.WriteTo(&out)
+		if !strings.Contains(out.String(), `cc_http_requests_total{method="GET",route="/user/:name",status="200"} 1`) || !strings.Contains(out.String(), "cc_http_requests_in_flight 1\n") {
+			t.Fatalf("http metrics error:\n%s\n", out.String())
+		}
+	})
+	t.Run("db stats", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		registry.Register(metrics.NewDBStatsCollector(func() sql.DBStats {
+			return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 4, WaitDuration: 1500 * time.Millisecond}
+		}))
+		var out strings.Builder
+		registry.WriteTo(&out)
+		for _, line := range []string{"cc_db_max_open_connections 10", "cc_db_in_use_connections 1", "cc_db_idle_connections 2", "cc_db_wait_count_total 4", "cc_db_wait_duration_seconds_total 1.5"} {
+			if !strings.Contains(out.String(), line+"\n") {
+				t.Fatalf("db stats line %q missing:\n%s\n", line, out.String())
+			}
+		}
+	})
+	t.Run("runtime", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		registry.Register(metrics.NewRuntimeCollector(), metrics.NewGaugeFunc("up", "Up.", func() float64 { return 1 }))
+		var out strings.Builder
+		registry.WriteTo(&out)
+		if !strings.Contains(out.String(), "# TYPE go_goroutines gauge\ngo_goroutines ") || !strings.Contains(out.String(), "up 1\n") {
+			t.Fatalf("runtime metrics error:\n%s\n", out.String())
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// RuntimeCollector Go 运行时指标采集器
+type RuntimeCollector struct {
+	start time.Time
+}
+
+// NewRuntimeCollector 构造 Go 运行时指标采集器
+func NewRuntimeCollector() *RuntimeCollector {
+	return &RuntimeCollector{start: time.Now()}
+}
+
+// Collect 实现 ICollector 接口
+func (collector *RuntimeCollector) Collect() []*Family {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	gauge := func(name, help string, v float64) *Family {
+		return &Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Value: v}}}
+	}
+	counter := func(name, help string, v float64) *Family {
+		return &Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Value: v}}}
+	}
+	return []*Family{
+		gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		{Name: "go_info", Help: "Information about the Go environment.", Type: TypeGauge, Samples: []Sample{{Labels: []Label{{Name: "version", Value: runtime.Version()}}, Value: 1}}},
+		gauge("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", float64(stats.Alloc)),
+		counter("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", float64(stats.TotalAlloc)),
+		gauge("go_memstats_sys_bytes", "Number of bytes obtained from system.", float64(stats.Sys)),
+		gauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(stats.HeapInuse)),
+		gauge("go_memstats_heap_objects", "Number of allocated objects.", float64(stats.HeapObjects)),
+		counter("go_memstats_mallocs_total", "Total number of mallocs.", float64(stats.Mallocs)),
+		counter("go_memstats_frees_total", "Total number of frees.", float64(stats.Frees)),
+		counter("go_gc_cycles_total", "Number of completed GC cycles.", float64(stats.NumGC)),
+		counter("go_gc_pause_seconds_total", "Total GC pause time in seconds.", float64(stats.PauseTotalNs)/1e9),
+		gauge("go_gomaxprocs", "Value of GOMAXPROCS.", float64(runtime.GOMAXPROCS(0))),
+		gauge("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", float64(collector.start.UnixNano())/1e9),
+	}
+}
@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Counter 计数器
+type Counter struct {
+	value atomicFloat
+}
+
+// Gauge 仪表盘
+type Gauge struct {
+	value atomicFloat
+}
+
+// Histogram 直方图
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomicFloat
+	sum         atomicFloat
+	count       atomicFloat
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	*vec[*Counter]
+}
+
+// GaugeVec 带标签的仪表盘
+type GaugeVec struct {
+	*vec[*Gauge]
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	*vec[*Histogram]
+	buckets []float64
+}
+
+// GaugeFunc 采集时计算取值的仪表盘
+type GaugeFunc struct {
+	name string
+	help string
+	f    func() float64
+}
+
+// vec 按标签值保存指标
+type vec[T any] struct {
+	name       string
+	help       string
+	labelNames []string
+	lock       sync.RWMutex
+	children   map[string]*child[T]
+	newMetric  func() T
+}
+
+// child 标签值对应的指标
+type child[T any] struct {
+	values []string
+	metric T
+}
+
+// NewCounterVec 构造带标签的计数器
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, labelNames, func() *Counter { return &Counter{} })}
+}
+
+// NewGaugeVec 构造带标签的仪表盘
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{newVec(name, help, labelNames, func() *Gauge { return &Gauge{} })}
+}
+
+// NewHistogramVec 构造带标签的直方图，buckets 为空时使用 DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{
+		vec: newVec(name, help, labelNames, func() *Histogram {
+			return &Histogram{upperBounds: buckets, counts: make([]atomicFloat, len(buckets))}
+		}),
+		buckets: buckets,
+	}
+}
+
+// NewGaugeFunc 构造采集时计算取值的仪表盘
+func NewGaugeFunc(name, help string, f func() float64) *GaugeFunc {
+	return &GaugeFunc{name: name, help: help, f: f}
+}
+
+// newVec 构造标签向量
+func newVec[T any](name, help string, labelNames []string, newMetric func() T) *vec[T] {
+	return &vec[T]{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		children:   make(map[string]*child[T]),
+		newMetric:  newMetric,
+	}
+}
+
+// With 获取标签值对应的指标，标签值数量需与标签名一致
+func (v *vec[T]) With(values ...string) T {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labelNames), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.lock.RLock()
+	c, ok := v.children[key]
+	v.lock.RUnlock()
+	if ok {
+		return c.metric
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if c, ok = v.children[key]; !ok {
+		c = &child[T]{values: append([]string(nil), values...), metric: v.newMetric()}
+		v.children[key] = c
+	}
+	return c.metric
+}
+
+// sorted 按标签值排序获取所有指标
+func (v *vec[T]) sorted() []*child[T] {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	keys := make([]string, 0, len(v.children))
+	for key := range v.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	children := make([]*child[T], 0, len(keys))
+	for _, key := range keys {
+		children = append(children, v.children[key])
+	}
+	return children
+}
+
+// labels 构造标签
+func (v *vec[T]) labels(values []string, extra ...Label) []Label {
+	labels := make([]Label, 0, len(values)+len(extra))
+	for i, name := range v.labelNames {
+		labels = append(labels, Label{Name: name, Value: values[i]})
+	}
+	return append(labels, extra...)
+}
+
+// Inc 加一
+func (counter *Counter) Inc() {
+	counter.value.Add(1)
+}
+
+// Add 累加，计数器只能增加
+func (counter *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	counter.value.Add(v)
+}
+
+// Value 当前值
+func (counter *Counter) Value() float64 {
+	return counter.value.Load()
+}
+
+// Set 设置
+func (gauge *Gauge) Set(v float64) {
+	gauge.value.Set(v)
+}
+
+// Inc 加一
+func (gauge *Gauge) Inc() {
+	gauge.value.Add(1)
+}
+
+// Dec 减一
+func (gauge *Gauge) Dec() {
+	gauge.value.Add(-1)
+}
+
+// Add 累加
+func (gauge *Gauge) Add(v float64) {
+	gauge.value.Add(v)
+}
+
+// Value 当前值
+func (gauge *Gauge) Value() float64 {
+	return gauge.value.Load()
+}
+
+// Observe 记录观测值
+func (histogram *Histogram) Observe(v float64) {
+	for i, bound := range histogram.upperBounds {
+		if v <= bound {
+			histogram.counts[i].Add(1)
+			break
+		}
+	}
+	histogram.sum.Add(v)
+	histogram.count.Add(1)
+}
+
+// Count 观测次数
+func (histogram *Histogram) Count() float64 {
+	return histogram.count.Load()
+}
+
+// Collect 实现 ICollector 接口
+func (counter *CounterVec) Collect() []*Family {
+	family := &Family{Name: counter.name, Help: counter.help, Type: TypeCounter}
+	for _, c := range counter.sorted() {
+		family.Samples = append(family.Samples, Sample{Labels: counter.labels(c.values), Value: c.metric.Value()})
+	}
+	return []*Family{family}
+}
+
+// Collect 实现 ICollector 接口
+func (gauge *GaugeVec) Collect() []*Family {
+	family := &Family{Name: gauge.name, Help: gauge.help, Type: TypeGauge}
+	for _, c := range gauge.sorted() {
+		family.Samples = append(family.Samples, Sample{Labels: gauge.labels(c.values), Value: c.metric.Value()})
+	}
+	return []*Family{family}
+}
+
+// Collect 实现 ICollector 接口
+func (histogram *HistogramVec) Collect() []*Family {
+	family := &Family{Name: histogram.name, Help: histogram.help, Type: TypeHistogram}
+	for _, c := range histogram.sorted() {
+		cumulative := 0.0
+		for i, bound := range histogram.buckets {
+			cumulative += c.metric.counts[i].Load()
+			family.Samples = append(family.Samples, Sample{Suffix: "_bucket", Labels: histogram.labels(c.values, Label{Name: "le", Value: formatFloat(bound)}), Value: cumulative})
+		}
+		count := c.metric.count.Load()
+		family.Samples = append(family.Samples,
+			Sample{Suffix: "_bucket", Labels: histogram.labels(c.values, Label{Name: "le", Value: formatFloat(math.Inf(1))}), Value: count},
+			Sample{Suffix: "_sum", Labels: histogram.labels(c.values), Value: c.metric.sum.Load()},
+			Sample{Suffix: "_count", Labels: histogram.labels(c.values), Value: count},
+		)
+	}
+	return []*Family{family}
+}
+
+// Collect 实现 ICollector 接口
+func (gauge *GaugeFunc) Collect() []*Family {
+	return []*Family{{Name: gauge.name, Help: gauge.help, Type: TypeGauge, Samples: []Sample{{Value: gauge.f()}}}}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/cquestor/cc"
+)
+
+// 访问日志格式
+const (
+	AccessLogCommon   = "common"   // NCSA Common Log Format
+	AccessLogCombined = "combined" // NCSA Combined Log Format
+	AccessLogJSON     = "json"     // 每行一个 JSON 对象
+)
+
+// AccessLogMiddleware 访问日志中间件
+type AccessLogMiddleware struct {
+	Format     string    // 日志格式，默认 combined
+	Template   string    // 自定义 text/template 模板，设置后忽略 Format
+	Output     io.Writer // 日志输出，默认 os.Stdout
+	Skips      []string  // 不记录的路径，以 "*" 结尾时按前缀匹配
+	SampleRate float64   // 成功请求 (状态码 < 400) 的采样率，取值 (0, 1)，其余值表示全部记录
+	lock       sync.Mutex
+}
+
+// AccessLogEntry 访问日志条目，同时作为自定义模板的数据
+type AccessLogEntry struct {
+	Time      time.Time
+	RemoteIP  string
+	User      string
+	Method    string
+	URI       string
+	Path      string
+	Proto     string
+	Status    int
+	Size      int64
+	Latency   time.Duration
+	Referer   string
+	UserAgent string
+	RequestID string
+}
+
+// SetSkips 设置不记录的路径
+func (log *AccessLogMiddleware) SetSkips(paths ...string) {
+	log.Skips = paths
+}
+
+// Instance 访问日志记录
+func (log *AccessLogMiddleware) Instance() func(*cc.Context) cc.Response {
+	if log.Output == nil {
+		log.Output = os.Stdout
+	}
+	if log.Format == "" {
+		log.Format = AccessLogCombined
+	}
+	var tmpl *template.Template
+	if log.Template != "" {
+		tmpl = template.Must(template.New("access").Parse(log.Template))
+	} else if log.Format != AccessLogCommon && log.Format != AccessLogCombined && log.Format != AccessLogJSON {
+		panic("unknown access log format: " + log.Format)
+	}
+	return func(ctx *cc.Context) cc.Response {
+		if log.skip(ctx.Path) {
+			return nil
+		}
+		start := time.Now()
+		writer := cc.WrapWriter(ctx.Writer)
+		ctx.Writer = writer
+		ctx.OnFinish(func() {
+			status := writer.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status < http.StatusBadRequest && log.SampleRate > 0 && log.SampleRate < 1 && rand.Float64() >= log.SampleRate {
+				return
+			}
+			entry := &AccessLogEntry{
+				Time:      start,
+				RemoteIP:  ctx.ClientIP(),
+				User:      remoteUser(ctx.Req),
+				Method:    ctx.Method,
+				URI:       ctx.Req.RequestURI,
+				Path:      ctx.Path,
+				Proto:     ctx.Req.Proto,
+				Status:    status,
+				Size:      writer.Size(),
+				Latency:   time.Since(start),
+				Referer:   ctx.Req.Referer(),
+				UserAgent: ctx.Req.UserAgent(),
+				RequestID: ctx.RequestID(),
+			}
+			if entry.URI == "" {
+				entry.URI = ctx.Req.URL.RequestURI()
+			}
+			log.write(entry, tmpl)
+		})
+		return nil
+	}
+}
+
+// skip 判断路径是否不记录
+func (log *AccessLogMiddleware) skip(path string) bool {
+	for _, each := range log.Skips {
+		if strings.HasSuffix(each, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(each, "*")) {
+				return true
+			}
+		} else if each == path {
+			return true
+		}
+	}
+	return false
+}
+
+// write 格式化并输出日志
+func (log *AccessLogMiddleware) write(entry *AccessLogEntry, tmpl *template.Template) {
+	var buf bytes.Buffer
+	switch {
+	case tmpl != nil:
+		if err := tmpl.Execute(&buf, entry); err != nil {
+			cc.LogErrf("access log template error: %v", err)
+			return
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteString("\n")
+		}
+	case log.Format == AccessLogJSON:
+		fields := map[string]any{
+			"time":       entry.Time.Format(time.RFC3339Nano),
+			"remote_ip":  entry.RemoteIP,
+			"user":       entry.User,
+			"method":     entry.Method,
+			"uri":        entry.URI,
+			"proto":      entry.Proto,
+			"status":     entry.Status,
+			"size":       entry.Size,
+			"latency_ms": float64(entry.Latency.Microseconds()) / 1000,
+			"referer":    entry.Referer,
+			"user_agent": entry.UserAgent,
+		}
+		if entry.RequestID != "" {
+			fields["request_id"] = entry.RequestID
+		}
+		json.NewEncoder(&buf).Encode(fields)
+	default:
+		fmt.Fprintf(&buf, "%s %s %s [%s] \"%s %s %s\" %d %s", entry.RemoteIP, "-", orDash(entry.User), entry.Time.Format("02/Jan/2006:15:04:05 -0700"), entry.Method, entry.URI, entry.Proto, entry.Status, sizeOrDash(entry.Size))
+		if log.Format == AccessLogCombined {
+			fmt.Fprintf(&buf, " %q %q", orDash(entry.Referer), orDash(entry.UserAgent))
+		}
+		buf.WriteString("\n")
+	}
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	log.Output.Write(buf.Bytes())
+}
+
+// remoteUser 获取 Basic 认证用户名
+func remoteUser(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok {
+		return user
+	}
+	return ""
+}
+
+// orDash 空值使用 "-" 代替
+func orDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// sizeOrDash 响应大小为 0 时使用 "-" 代替
+func sizeOrDash(v int64) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/cquestor/cc"
@@ -9,14 +10,24 @@ import (
 
 // CorsMiddleware 跨域中间件
 type CorsMiddleware struct {
-	Origin  string
-	Methods string
-	Headers string
+	Origin           string                   // 允许的源，逗号分隔，已废弃，使用 Origins
+	Origins          []string                 // 允许的源，支持 "*" 及 "https://*.example.com" 形式的子域名通配
+	OriginFunc       func(origin string) bool // 自定义源校验，优先于 Origins
+	Methods          string                   // 允许的方法，逗号分隔，"*" 表示任意
+	Headers          string                   // 允许的请求头，逗号分隔，"*" 表示任意
+	ExposeHeaders    []string                 // 允许前端读取的响应头
+	AllowCredentials bool                     // 是否允许携带凭证
+	MaxAge           int                      // 预检结果缓存时间，单位秒
 }
 
-// SetOrigin设置跨域源
-func (cors *CorsMiddleware) SetOrigin(origin string) {
-	cors.Origin = origin
+// SetOrigin 设置跨域源
+func (cors *CorsMiddleware) SetOrigin(origins ...string) {
+	cors.Origins = origins
+}
+
+// SetOriginFunc 设置跨域源校验函数
+func (cors *CorsMiddleware) SetOriginFunc(f func(origin string) bool) {
+	cors.OriginFunc = f
 }
 
 // SetMethods 设置跨域方法
@@ -29,24 +40,210 @@ func (cors *CorsMiddleware) SetHeaders(headers ...string) {
 	cors.Headers = strings.Join(headers, ", ")
 }
 
-// Cors 跨域设置
+// SetExposeHeaders 设置暴露的响应头
+func (cors *CorsMiddleware) SetExposeHeaders(headers ...string) {
+	cors.ExposeHeaders = headers
+}
+
+// Instance 跨域设置
 func (cors *CorsMiddleware) Instance() func(*cc.Context) cc.Response {
-	if cors.Origin == "" {
-		cors.Origin = "*"
+	if cors.Origin != "" {
+		cors.Origins = append(cors.Origins, splitList(cors.Origin)...)
+	}
+	if len(cors.Origins) == 0 && cors.OriginFunc == nil {
+		cors.Origins = []string{"*"}
 	}
 	if cors.Methods == "" {
-		cors.Methods = "*"
+		cors.SetMethods(http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead)
 	}
 	if cors.Headers == "" {
-		cors.Methods = "*"
+		cors.Headers = "*"
 	}
+	policy := newCorsPolicy(cors)
 	return func(ctx *cc.Context) cc.Response {
-		ctx.SetHeader("Access-Control-Allow-Origin", cors.Origin)
-		ctx.SetHeader("Access-Control-Allow-Methods", cors.Methods)
-		ctx.SetHeader("Access-Control-Allow-Headers", cors.Headers)
-		if ctx.Method == http.MethodOptions {
-			return cc.Code(http.StatusNoContent)
+		origin := ctx.Header("Origin")
+		preflight := ctx.Method == http.MethodOptions && ctx.Header("Access-Control-Request-Method") != ""
+		if policy.echoOrigin() {
+			ctx.Writer.Header().Add("Vary", "Origin")
+		}
+		if preflight {
+			ctx.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			ctx.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" {
+			return nil
+		}
+		if !policy.allowOrigin(origin) {
+			if preflight {
+				return cc.Code(http.StatusForbidden)
+			}
+			return nil
+		}
+		policy.setOrigin(ctx, origin)
+		if !preflight {
+			if policy.expose != "" {
+				ctx.SetHeader("Access-Control-Expose-Headers", policy.expose)
+			}
+			return nil
+		}
+		method := ctx.Header("Access-Control-Request-Method")
+		if !policy.allowMethod(method) {
+			return cc.Code(http.StatusForbidden)
+		}
+		headers := parseHeaderList(ctx.Header("Access-Control-Request-Headers"))
+		if !policy.allowHeaders(headers) {
+			return cc.Code(http.StatusForbidden)
+		}
+		if policy.anyMethod {
+			ctx.SetHeader("Access-Control-Allow-Methods", method)
+		} else {
+			ctx.SetHeader("Access-Control-Allow-Methods", policy.methods)
+		}
+		if len(headers) > 0 {
+			ctx.SetHeader("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+		if cors.MaxAge > 0 {
+			ctx.SetHeader("Access-Control-Max-Age", strconv.Itoa(cors.MaxAge))
+		}
+		return cc.Code(http.StatusNoContent)
+	}
+}
+
+// corsPolicy 预处理后的跨域策略
+type corsPolicy struct {
+	cors      *CorsMiddleware
+	allowAll  bool
+	origins   map[string]struct{}
+	wildcards [][2]string
+	methods   string
+	anyMethod bool
+	methodSet map[string]struct{}
+	anyHeader bool
+	headerSet map[string]struct{}
+	expose    string
+}
+
+// newCorsPolicy 构造跨域策略
+func newCorsPolicy(cors *CorsMiddleware) *corsPolicy {
+	policy := &corsPolicy{
+		cors:      cors,
+		origins:   make(map[string]struct{}),
+		methodSet: make(map[string]struct{}),
+		headerSet: make(map[string]struct{}),
+		expose:    strings.Join(cors.ExposeHeaders, ", "),
+	}
+	for _, origin := range cors.Origins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			policy.allowAll = true
+		case strings.Contains(origin, "*"):
+			index := strings.Index(origin, "*")
+			policy.wildcards = append(policy.wildcards, [2]string{origin[:index], origin[index+1:]})
+		default:
+			policy.origins[origin] = struct{}{}
+		}
+	}
+	methods := make([]string, 0)
+	for _, method := range splitList(cors.Methods) {
+		if method == "*" {
+			policy.anyMethod = true
+			continue
+		}
+		method = strings.ToUpper(method)
+		policy.methodSet[method] = struct{}{}
+		methods = append(methods, method)
+	}
+	policy.methods = strings.Join(methods, ", ")
+	for _, header := range splitList(cors.Headers) {
+		if header == "*" {
+			policy.anyHeader = true
+			continue
+		}
+		policy.headerSet[strings.ToLower(header)] = struct{}{}
+	}
+	return policy
+}
+
+// allowOrigin 判断源是否允许
+func (policy *corsPolicy) allowOrigin(origin string) bool {
+	if policy.cors.OriginFunc != nil {
+		return policy.cors.OriginFunc(origin)
+	}
+	if policy.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if _, ok := policy.origins[origin]; ok {
+		return true
+	}
+	for _, wildcard := range policy.wildcards {
+		if len(origin) > len(wildcard[0])+len(wildcard[1]) && strings.HasPrefix(origin, wildcard[0]) && strings.HasSuffix(origin, wildcard[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// echoOrigin 是否回显请求源，此时响应随 Origin 变化
+func (policy *corsPolicy) echoOrigin() bool {
+	return !policy.allowAll || policy.cors.AllowCredentials || policy.cors.OriginFunc != nil
+}
+
+// setOrigin 设置允许的源，携带凭证时不能使用 "*"
+func (policy *corsPolicy) setOrigin(ctx *cc.Context, origin string) {
+	if policy.echoOrigin() {
+		ctx.SetHeader("Access-Control-Allow-Origin", origin)
+	} else {
+		ctx.SetHeader("Access-Control-Allow-Origin", "*")
+	}
+	if policy.cors.AllowCredentials {
+		ctx.SetHeader("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowMethod 判断预检方法是否允许，简单方法总是允许
+func (policy *corsPolicy) allowMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+		return true
+	}
+	if policy.anyMethod {
+		return true
+	}
+	_, ok := policy.methodSet[strings.ToUpper(method)]
+	return ok
+}
+
+// allowHeaders 判断预检请求头是否允许，响应中总是回显具体请求头而非 "*"
+func (policy *corsPolicy) allowHeaders(headers []string) bool {
+	if policy.anyHeader {
+		return true
+	}
+	for _, header := range headers {
+		if _, ok := policy.headerSet[header]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseHeaderList 解析逗号分隔的请求头列表
+func parseHeaderList(v string) []string {
+	headers := splitList(v)
+	for i, header := range headers {
+		headers[i] = strings.ToLower(header)
+	}
+	return headers
+}
+
+// splitList 拆分逗号分隔列表，去除空白项
+func splitList(v string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-		return nil
 	}
+	return items
 }
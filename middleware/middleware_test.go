@@ -0,0 +1,271 @@
+package middleware_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cquestor/cc"
+	"github.com/cquestor/cc/logger"
+	"github.com/cquestor/cc/middleware"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		secure := middleware.NewSecureHeadersMiddleware()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		ctx := cc.NewContext(w, r, nil)
+		if response := secure.Instance()(ctx); response != nil {
+			t.Fatalf("secure headers should not stop the request")
+		}
+		if w.Header().Get("X-Frame-Options") != "DENY" || w.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Fatalf("default headers missing: %+v\n", w.Header())
+		}
+		if w.Header().Get("Strict-Transport-Security") != "" {
+			t.Fatalf("hsts should only be sent over https")
+		}
+	})
+	t.Run("hsts", func(t *testing.T) {
+		secure := middleware.NewSecureHeadersMiddleware()
+		secure.HSTS.Preload = true
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.TLS = &tls.ConnectionState{}
+		w := httptest.NewRecorder()
+		secure.Instance()(cc.NewContext(w, r, nil))
+		if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "max-age=31536000; includeSubDomains; preload" {
+			t.Fatalf("hsts header error: %s\n", hsts)
+		}
+	})
+	t.Run("nonce", func(t *testing.T) {
+		secure := middleware.NewSecureHeadersMiddleware()
+		secure.SetCSP("default-src 'self'", "script-src 'self' "+middleware.NoncePlaceholder)
+		secure.CSP.Nonce = true
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		ctx := cc.NewContext(w, r, nil)
+		secure.Instance()(ctx)
+		csp := w.Header().Get("Content-Security-Policy")
+		if ctx.Nonce() == "" || !strings.Contains(csp, "'nonce-"+ctx.Nonce()+"'") {
+			t.Fatalf("csp nonce error: %s %s\n", ctx.Nonce(), csp)
+		}
+	})
+}
+
+func TestCors(t *testing.T) {
+	cors := middleware.CorsMiddleware{}
+	cors.SetOrigin("https://example.com", "https://*.example.org")
+	cors.SetMethods(http.MethodGet, http.MethodPut)
+	cors.SetHeaders("Content-Type", "X-Token")
+	cors.SetExposeHeaders("X-Total")
+	cors.AllowCredentials = true
+	cors.MaxAge = 600
+	handler := cors.Instance()
+	request := func(method, origin string, headers map[string]string) (cc.Response, *httptest.ResponseRecorder) {
+		r := httptest.NewRequest(method, "/", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		for key, value := range headers {
+			r.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		return handler(cc.NewContext(w, r, nil)), w
+	}
+	t.Run("simple", func(t *testing.T) {
+		response, w := request(http.MethodGet, "https://api.example.org", nil)
+		if response != nil {
+			t.Fatalf("simple request should pass through")
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "https://api.example.org" || w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Header().Get("Access-Control-Expose-Headers") != "X-Total" {
+			t.Fatalf("simple request headers error: %+v\n", w.Header())
+		}
+		if w.Header().Get("Vary") != "Origin" {
+			t.Fatalf("vary header missing: %+v\n", w.Header())
+		}
+	})
+	t.Run("disallowed", func(t *testing.T) {
+		_, w := request(http.MethodGet, "https://evil.com", nil)
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("disallowed origin should not be echoed")
+		}
+		_, w = request(http.MethodGet, "https://example.org", nil)
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("wildcard should only match subdomains")
+		}
+	})
+	t.Run("preflight", func(t *testing.T) {
+		response, w := request(http.MethodOptions, "https://example.com", map[string]string{
+			"Access-Control-Request-Method":  http.MethodPut,
+			"Access-Control-Request-Headers": "content-type, x-token",
+		})
+		response.Invoke(cc.NewContext(w, httptest.NewRequest(http.MethodOptions, "/", nil), nil))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("preflight status error: %d\n", w.Code)
+		}
+		if w.Header().Get("Access-Control-Allow-Methods") != "GET, PUT" || w.Header().Get("Access-Control-Allow-Headers") != "content-type, x-token" || w.Header().Get("Access-Control-Max-Age") != "600" {
+			t.Fatalf("preflight headers error: %+v\n", w.Header())
+		}
+	})
+	t.Run("preflight rejected", func(t *testing.T) {
+		response, _ := request(http.MethodOptions, "https://example.com", map[string]string{
+			"Access-Control-Request-Method": http.MethodDelete,
+		})
+		if response == nil {
+			t.Fatalf("disallowed method should be rejected")
+		}
+		response, _ = request(http.MethodOptions, "https://example.com", map[string]string{
+			"Access-Control-Request-Method":  http.MethodGet,
+			"Access-Control-Request-Headers": "x-unknown",
+		})
+		if response == nil {
+			t.Fatalf("disallowed header should be rejected")
+		}
+	})
+	t.Run("plain options", func(t *testing.T) {
+		if response, _ := request(http.MethodOptions, "https://example.com", nil); response != nil {
+			t.Fatalf("non-preflight options should reach the handler")
+		}
+	})
+	t.Run("string fields", func(t *testing.T) {
+		preflight := func(cors middleware.CorsMiddleware, method string) (cc.Response, *httptest.ResponseRecorder) {
+			r := httptest.NewRequest(http.MethodOptions, "/", nil)
+			r.Header.Set("Origin", "https://legacy.com")
+			r.Header.Set("Access-Control-Request-Method", method)
+			r.Header.Set("Access-Control-Request-Headers", "x-token")
+			w := httptest.NewRecorder()
+			return cors.Instance()(cc.NewContext(w, r, nil)), w
+		}
+		response, w := preflight(middleware.CorsMiddleware{Origin: "https://legacy.com", Methods: "GET, DELETE", Headers: "X-Token"}, http.MethodDelete)
+		if response == nil || w.Header().Get("Access-Control-Allow-Origin") != "https://legacy.com" || w.Header().Get("Access-Control-Allow-Methods") != "GET, DELETE" {
+			t.Fatalf("comma separated fields should be accepted: %+v\n", w.Header())
+		}
+		if _, w := preflight(middleware.CorsMiddleware{Origin: "https://legacy.com", Methods: "GET", Headers: "X-Token"}, http.MethodDelete); w.Header().Get("Access-Control-Allow-Methods") != "" {
+			t.Fatalf("method missing from the list should be rejected")
+		}
+		if _, w := preflight(middleware.CorsMiddleware{Methods: "*"}, http.MethodPatch); w.Header().Get("Access-Control-Allow-Methods") != http.MethodPatch {
+			t.Fatalf("any method should echo the requested method: %+v\n", w.Header())
+		}
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	engine := cc.New()
+	access := middleware.AccessLogMiddleware{Format: middleware.AccessLogJSON, Output: &buf}
+	access.SetSkips("/healthz", "/static/*")
+	engine.Before(access.Instance())
+	engine.Get("/hello", func(ctx *cc.Context) cc.Response {
+		return cc.String(http.StatusCreated, "hello")
+	})
+	engine.Get("/healthz", func(ctx *cc.Context) cc.Response {
+		return cc.Code(http.StatusOK)
+	})
+	serve := func(path string) {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Forwarded-For", "1.2.3.4")
+		engine.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	t.Run("json", func(t *testing.T) {
+		serve("/hello?name=cc")
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry["status"] != float64(http.StatusCreated) || entry["size"] != float64(5) || entry["uri"] != "/hello?name=cc" || entry["remote_ip"] != "192.0.2.1" {
+			t.Fatalf("access log entry error: %+v\n", entry)
+		}
+		buf.Reset()
+	})
+	t.Run("skip", func(t *testing.T) {
+		serve("/healthz")
+		serve("/static/app.js")
+		if buf.Len() != 0 {
+			t.Fatalf("skipped paths should not be logged: %s\n", buf.String())
+		}
+	})
+	t.Run("common", func(t *testing.T) {
+		var out bytes.Buffer
+		engine := cc.New()
+		access := middleware.AccessLogMiddleware{Format: middleware.AccessLogCommon, Output: &out}
+		engine.Before(access.Instance())
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+		if !regexp.MustCompile(`^192\.0\.2\.1 - - \[.+\] "GET /missing HTTP/1\.1" 404 23\n$`).MatchString(out.String()) {
+			t.Fatalf("common entry error: %q\n", out.String())
+		}
+	})
+	t.Run("template", func(t *testing.T) {
+		var out bytes.Buffer
+		engine := cc.New()
+		access := middleware.AccessLogMiddleware{Template: "{{.Method}} {{.Path}} {{.Status}}", Output: &out}
+		engine.Before(access.Instance())
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+		if out.String() != "GET /missing 404\n" {
+			t.Fatalf("template entry error: %q\n", out.String())
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	cc.SetLogger(logger.New(logger.LevelInfo, logger.NewJSONOutput(&buf)))
+	defer cc.SetLogger(logger.New(logger.LevelInfo, logger.NewConsoleOutput(os.Stderr)))
+	engine := cc.New()
+	rid := middleware.RequestIDMiddleware{}
+	engine.Before(rid.Instance())
+	engine.Get("/", func(ctx *cc.Context) cc.Response {
+		ctx.Logger().Info("handling")
+		cc.LogInfoCtx(ctx.Req.Context(), "handled", ctx.Req.URL.Path)
+		cc.LogInfof("plain %s", ctx.Req.URL.Path)
+		return cc.String(http.StatusOK, ctx.RequestID())
+	})
+	t.Run("generate", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		id := w.Header().Get("X-Request-ID")
+		if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) || w.Body.String() != id {
+			t.Fatalf("generated request id error: %s %s\n", id, w.Body.String())
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("three log entries expected: %q\n", buf.String())
+		}
+		for _, line := range lines {
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry["request_id"] != id {
+				t.Fatalf("log entry should be tagged with request id: %+v\n", entry)
+			}
+		}
+		buf.Reset()
+		cc.LogInfo("outside request")
+		if strings.Contains(buf.String(), "request_id") {
+			t.Fatalf("log entry outside a request should not be tagged: %s\n", buf.String())
+		}
+		buf.Reset()
+	})
+	t.Run("incoming", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Request-ID", "upstream-42")
+		engine.ServeHTTP(w, r)
+		if w.Header().Get("X-Request-ID") != "upstream-42" {
+			t.Fatalf("incoming request id should be kept: %s\n", w.Header().Get("X-Request-ID"))
+		}
+		r.Header.Set("X-Request-ID", "bad id\nforged")
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		if w.Header().Get("X-Request-ID") == "bad id\nforged" {
+			t.Fatalf("invalid incoming request id should be replaced")
+		}
+		buf.Reset()
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/cquestor/cc"
+)
+
+// RequestIDMiddleware 请求 ID 中间件，请求 ID 附加到请求上下文的日志字段中，
+// 请求处理过程中 cc.LogInfo、ctx.Logger() 等输出的日志自动携带 request_id；
+// 处理器中另起的协程需通过 ctx.Logger() 或 cc.LogInfoCtx(ctx.Req.Context(), ...) 输出
+type RequestIDMiddleware struct {
+	Header         string        // 请求 ID 请求头及响应头，默认 X-Request-ID
+	Generator      func() string // 请求 ID 生成函数，默认 UUIDv7
+	IgnoreIncoming bool          // 是否忽略请求中携带的 ID，总是重新生成
+}
+
+// Instance 请求 ID 设置
+func (rid *RequestIDMiddleware) Instance() func(*cc.Context) cc.Response {
+	if rid.Header == "" {
+		rid.Header = "X-Request-ID"
+	}
+	if rid.Generator == nil {
+		rid.Generator = UUIDv7
+	}
+	return func(ctx *cc.Context) cc.Response {
+		id := ""
+		if !rid.IgnoreIncoming {
+			id = ctx.Header(rid.Header)
+		}
+		if !validRequestID(id) {
+			id = rid.Generator()
+		}
+		ctx.SetRequestID(id)
+		ctx.SetHeader(rid.Header, id)
+		return nil
+	}
+}
+
+// UUIDv7 生成 RFC 9562 UUIDv7，按时间有序
+func UUIDv7() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		panic(err)
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(uuid[:6], ms[2:])
+	uuid[6] = uuid[6]&0x0f | 0x70
+	uuid[8] = uuid[8]&0x3f | 0x80
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+	return string(buf[:])
+}
+
+// validRequestID 校验外部传入的请求 ID，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
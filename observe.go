@@ -0,0 +1,184 @@
+package cc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cquestor/cc/metrics"
+	"github.com/cquestor/cc/tracing"
+)
+
+// unmatchedRoute 未匹配到路由时的指标标签
+const unmatchedRoute = "<unmatched>"
+
+// queryStartKey 查询开始时间的上下文键
+type queryStartKey struct{}
+
+// metricsHook 记录查询耗时的 orm 钩子
+type metricsHook struct {
+	metrics *metrics.QueryMetrics
+}
+
+// Metrics 获取指标注册表，可注册自定义指标
+func (engine *Engine) Metrics() *metrics.Registry {
+	if engine.registry == nil {
+		engine.registry = metrics.NewRegistry()
+	}
+	return engine.registry
+}
+
+// initMetrics 依据配置初始化指标采集
+func (engine *Engine) initMetrics() {
+	if !engine.config.Metrics.Enabled {
+		return
+	}
+	registry := engine.Metrics()
+	registry.Register(metrics.NewRuntimeCollector())
+	engine.httpMetrics = metrics.NewHTTPMetrics(registry)
+	if engine.database != nil {
+		engine.database.AddHook(&metricsHook{metrics: metrics.NewQueryMetrics(registry)})
+		registry.Register(metrics.NewDBStatsCollector(engine.database.Stats))
+	}
+	engine.addRoute(http.MethodGet, engine.config.Metrics.Path, Handler(func(ctx *Context) Response {
+		ctx.SetHeader("Content-Type", metrics.ContentType)
+		ctx.setStatusCode(http.StatusOK)
+		registry.WriteTo(ctx.Writer)
+		return nil
+	}))
+	LogInfof("Metrics exposed at %s", engine.config.Metrics.Path)
+}
+
+// logPoolStats 依据 database.stats-interval 定期记录连接池统计信息，服务关闭后停止
+func (engine *Engine) logPoolStats() {
+	interval := time.Duration(engine.config.Database.StatsInterval) * time.Second
+	if engine.database == nil || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				stats := engine.database.Stats()
+				LogInfof("Database pool: open=%d/%d in-use=%d idle=%d wait-count=%d wait-duration=%s closed(idle=%d idle-time=%d lifetime=%d)",
+					stats.OpenConnections, stats.MaxOpenConnections, stats.InUse, stats.Idle, stats.WaitCount, stats.WaitDuration,
+					stats.MaxIdleClosed, stats.MaxIdleTimeClosed, stats.MaxLifetimeClosed)
+			case <-engine.stopped:
+				return
+			}
+		}
+	}()
+}
+
+// instrument 记录请求指标
+func (engine *Engine) instrument(ctx *Context) {
+	if engine.httpMetrics == nil {
+		return
+	}
+	start := time.Now()
+	engine.httpMetrics.InFlight.Inc()
+	writer := WrapWriter(ctx.Writer)
+	ctx.Writer = writer
+	ctx.OnFinish(func() {
+		engine.httpMetrics.InFlight.Dec()
+		status := writer.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := ctx.Route
+		if route == "" {
+			route = unmatchedRoute
+		}
+		engine.httpMetrics.Observe(ctx.Method, route, status, time.Since(start))
+	})
+}
+
+// Tracer 获取链路追踪器，未开启追踪时返回 nil
+func (engine *Engine) Tracer() *tracing.Tracer {
+	return engine.tracer
+}
+
+// initTracing 依据配置初始化链路追踪
+func (engine *Engine) initTracing() error {
+	config := engine.config.Tracing
+	if !config.Enabled {
+		return nil
+	}
+	var exporter tracing.IExporter
+	switch config.Exporter {
+	case "stdout", "":
+		exporter = tracing.NewStdoutExporter(nil)
+	case "otlp":
+		exporter = tracing.NewOTLPExporter(config.Endpoint, config.ServiceName)
+	default:
+		return fmt.Errorf("unknown tracing exporter: %s", config.Exporter)
+	}
+	engine.tracer = tracing.NewTracer(exporter, config.SampleRatio)
+	engine.tracer.SetErrorHandler(func(err error) {
+		LogWarnf("Tracing: %v", err)
+	})
+	if engine.database != nil {
+		engine.database.AddHook(tracing.NewQueryHook(engine.tracer, engine.database.Dialect().Name()))
+	}
+	LogInfof("Tracing enabled, exporting spans to %s", config.Exporter)
+	return nil
+}
+
+// trace 为请求创建服务端跨度，跨度名在请求结束后依据路由模式确定
+func (engine *Engine) trace(ctx *Context) {
+	if engine.tracer == nil {
+		return
+	}
+	parent := ctx.Req.Context()
+	if sc, ok := tracing.Extract(ctx.Req.Header); ok {
+		parent = tracing.ContextWithRemote(parent, sc)
+	}
+	spanCtx, span := engine.tracer.Start(parent, ctx.Method, tracing.KindServer,
+		"http.request.method", ctx.Method,
+		"url.path", ctx.Path,
+		"client.address", ctx.ClientIP(),
+	)
+	ctx.Req = ctx.Req.WithContext(spanCtx)
+	if ctx.session != nil {
+		ctx.session.WithContext(spanCtx)
+	}
+	writer := WrapWriter(ctx.Writer)
+	ctx.Writer = writer
+	ctx.OnFinish(func() {
+		status := writer.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if ctx.Route != "" {
+			span.SetName(ctx.Method + " " + ctx.Route)
+			span.SetAttributes("http.route", ctx.Route)
+		}
+		span.SetAttributes("http.response.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, http.StatusText(status))
+		}
+		span.End()
+	})
+}
+
+// BeforeQuery 实现 orm.IHook 接口
+func (hook *metricsHook) BeforeQuery(ctx context.Context, query string, args []any) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+// AfterQuery 实现 orm.IHook 接口
+func (hook *metricsHook) AfterQuery(ctx context.Context, query string, err error) {
+	if start, ok := ctx.Value(queryStartKey{}).(time.Time); ok {
+		hook.metrics.Observe(queryOperation(query), time.Since(start), err)
+	}
+}
+
+// queryOperation 获取 SQL 语句类型，如 select、insert
+func queryOperation(query string) string {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return strings.ToLower(operation)
+}
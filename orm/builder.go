@@ -0,0 +1,300 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// builder 查询构造器，Session 与 CTx 共用，T 为链式调用返回的类型
+type builder[T any] struct {
+	self        T
+	db          executor
+	dialect     IDialect
+	ctx         context.Context
+	hooks       []IHook
+	table       []string
+	sql         *strings.Builder
+	storeInsert *StoreInsert
+	storeWhere  []*StoreWhere
+	storeSet    []*StoreSet
+	storeLimit  *StoreLimit
+	storeOrder  []*StoreOrder
+	lastExec    sql.Result
+}
+
+// newBuilder 构造查询构造器
+func newBuilder[T any](self T, db executor, dialect IDialect, ctx context.Context, hooks []IHook) builder[T] {
+	return builder[T]{
+		self:    self,
+		db:      db,
+		dialect: dialect,
+		ctx:     ctx,
+		hooks:   hooks,
+		table:   make([]string, 0),
+		sql:     &strings.Builder{},
+		storeInsert: &StoreInsert{
+			fields:     make([]string, 0),
+			values:     make([][]any, 0),
+			prepareStr: &strings.Builder{},
+			execs:      make([]any, 0),
+		},
+		storeWhere: make([]*StoreWhere, 0),
+		storeSet:   make([]*StoreSet, 0),
+		storeLimit: &StoreLimit{},
+		storeOrder: make([]*StoreOrder, 0),
+	}
+}
+
+// LastExec 获取最近一次插入、修改、删除或建表的执行结果
+func (b *builder[T]) LastExec() sql.Result {
+	return b.lastExec
+}
+
+// WithContext 设置上下文，用于取消查询及传递给查询钩子
+func (b *builder[T]) WithContext(ctx context.Context) T {
+	b.ctx = ctx
+	return b.self
+}
+
+// Table 设置表格名
+func (b *builder[T]) Table(name string) T {
+	b.table = append(b.table, b.dialect.Quote(name))
+	return b.self
+}
+
+// Where 添加 Where 子句
+func (b *builder[T]) Where(field, flag string, v any) T {
+	b.storeWhere = append(b.storeWhere, &StoreWhere{prepareStr: fmt.Sprintf("%s %s ?", field, flag), exec: v})
+	return b.self
+}
+
+// Equal 相等 Where 子句
+func (b *builder[T]) Equal(field string, v any) T {
+	return b.Where(field, "=", v)
+}
+
+// Unequal 不相等 Where 子句
+func (b *builder[T]) Unequal(field string, v any) T {
+	return b.Where(field, "!=", v)
+}
+
+// Set 添加 Set 子句
+func (b *builder[T]) Set(field string, v any) T {
+	b.storeSet = append(b.storeSet, &StoreSet{prepareStr: fmt.Sprintf("%s = ?", field), exec: v})
+	return b.self
+}
+
+// Limit 添加 Limit 子句
+func (b *builder[T]) Limit(count int, offset ...int) T {
+	if len(offset) < 1 {
+		offset = append(offset, 0)
+	}
+	b.storeLimit.offset = offset[0]
+	b.storeLimit.count = count
+	return b.self
+}
+
+// Order 添加 Order By 子句
+func (b *builder[T]) Order(name string, desc ...bool) T {
+	if len(desc) < 1 {
+		desc = append(desc, false)
+	}
+	b.storeOrder = append(b.storeOrder, &StoreOrder{name: name, desc: desc[0]})
+	return b.self
+}
+
+// Update 修改数据
+func (b *builder[T]) Update() error {
+	defer b.Reset()
+	if len(b.table) < 1 {
+		return fmt.Errorf("table name is empty, forgot set it?")
+	}
+	b.sql.WriteString(fmt.Sprintf("UPDATE %s", b.table[0]))
+	execs := make([]any, 0)
+	addSets(b.sql, b.storeSet, &execs)
+	addWheres(b.sql, b.storeWhere, &execs)
+	return b._exec(execs...)
+}
+
+// Delete 删除数据
+func (b *builder[T]) Delete() error {
+	defer b.Reset()
+	if len(b.table) < 1 {
+		return fmt.Errorf("table name is empty, forgot set it?")
+	}
+	b.sql.WriteString(fmt.Sprintf("DELETE FROM %s", b.table[0]))
+	execs := make([]any, 0)
+	addWheres(b.sql, b.storeWhere, &execs)
+	return b._exec(execs...)
+}
+
+// CreateTable 依据结构体创建表，表已存在时忽略，列类型由方言映射
+func (b *builder[T]) CreateTable(v any) error {
+	defer b.Reset()
+	if len(b.table) < 1 {
+		return fmt.Errorf("table name is empty, forgot set it?")
+	}
+	query, err := createTableSQL(b.table[0], v, b.dialect)
+	if err != nil {
+		return err
+	}
+	b.sql.WriteString(query)
+	return b._exec()
+}
+
+// Insert 插入数据
+func (b *builder[T]) Insert(v ...any) error {
+	defer b.Reset()
+	if len(b.table) < 1 {
+		return fmt.Errorf("table name is empty, forgot set it?")
+	}
+	for _, each := range v {
+		each, err := checkInsertObject(each)
+		if err != nil {
+			return err
+		}
+		fields, values, _type := parseInsertObject(each, b.dialect)
+		if err := b._insert(fields, values, _type); err != nil {
+			return err
+		}
+	}
+	b.sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", b.table[0], strings.Join(b.storeInsert.fields, ", "), b.storeInsert.prepareStr.String()))
+	res, err := insertContext(b.ctx, b.db, b.hooks, b.dialect, b.sql.String(), b.storeInsert.execs, v)
+	b.lastExec = res
+	return err
+}
+
+// _insert 插入一条数据
+func (b *builder[T]) _insert(fields []string, values []any, _type reflect.Type) error {
+	if b.storeInsert.elem == nil {
+		b.storeInsert.elem = _type
+	}
+	if b.storeInsert.elem != _type {
+		return fmt.Errorf("all inserted objects must be of the same type (%s) <=> (%s)", b.storeInsert.elem.Name(), _type.Name())
+	}
+	b.storeInsert.fields = fields
+	b.storeInsert.values = append(b.storeInsert.values, values)
+	b.storeInsert.execs = append(b.storeInsert.execs, values...)
+	if b.storeInsert.prepareStr.String() != "" {
+		b.storeInsert.prepareStr.WriteString(", ")
+	}
+	b.storeInsert.prepareStr.WriteString(fmt.Sprintf("(%s)", strings.Join(genPrepare(len(values)), ", ")))
+	return nil
+}
+
+// Select 查询数据
+func (b *builder[T]) Select(v any) (int, error) {
+	defer b.Reset()
+	if len(b.table) < 1 {
+		return 0, fmt.Errorf("table name is empty, forgot set it?")
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr {
+		return 0, fmt.Errorf("the select target object must be a ptr, not %s", t.Kind())
+	}
+	_type, isSlice, isPointer, err := checkSelectObject(v)
+	if err != nil {
+		return 0, err
+	}
+	if isSlice {
+		rows, err := b._select(_type)
+		if err != nil {
+			return 0, err
+		}
+		n, values, err := b._select_fetch(_type, rows)
+		if err != nil {
+			return 0, err
+		}
+		_value := reflect.ValueOf(v)
+		for _, each := range values {
+			if isPointer {
+				_value.Elem().Set(reflect.Append(_value.Elem(), each))
+			} else {
+				_value.Elem().Set(reflect.Append(_value.Elem(), each.Elem()))
+			}
+		}
+		return n, nil
+	}
+	b.Limit(1)
+	rows, err := b._select(_type)
+	if err != nil {
+		return 0, err
+	}
+	return b._select_one(v, rows)
+}
+
+// _select 查询数据
+func (b *builder[T]) _select(t reflect.Type) (*sql.Rows, error) {
+	execs := make([]any, 0)
+	fields := parseSelectObject(t, b.dialect)
+	b.sql.WriteString(fmt.Sprintf("SELECT %s FROM %s", strings.Join(fields, ", "), strings.Join(b.table, ", ")))
+	addWheres(b.sql, b.storeWhere, &execs)
+	addOrders(b.sql, b.storeOrder, &execs)
+	addLimit(b.sql, b.storeLimit, &execs, b.dialect)
+	return queryContext(b.ctx, b.db, b.hooks, b.dialect, b.sql.String(), execs)
+}
+
+// _select_one 查询单条数据
+func (b *builder[T]) _select_one(v any, rows *sql.Rows) (int, error) {
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	if err := scanOneObject(v, rows); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// _select_fetch 查询多条数据
+func (b *builder[T]) _select_fetch(t reflect.Type, rows *sql.Rows) (int, []reflect.Value, error) {
+	defer rows.Close()
+	values := make([]reflect.Value, 0)
+	for rows.Next() {
+		value, err := scanFetchObject(t, rows)
+		if err != nil {
+			return 0, nil, err
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return len(values), values, nil
+}
+
+// _exec 执行 sql 语句并记录执行结果
+func (b *builder[T]) _exec(execs ...any) error {
+	res, err := execContext(b.ctx, b.db, b.hooks, b.dialect, b.sql.String(), execs)
+	b.lastExec = res
+	return err
+}
+
+// Reset 重置构造器，清除表格名及全部子句
+func (b *builder[T]) Reset() {
+	b.table = b.table[:0]
+	b.storeInsert.Clear()
+	b.storeWhere = b.storeWhere[:0]
+	b.storeSet = b.storeSet[:0]
+	b.storeLimit.Clear()
+	b.storeOrder = b.storeOrder[:0]
+	b.sql.Reset()
+}
+
+// Clear 清除插入缓存
+func (store *StoreInsert) Clear() {
+	store.elem = nil
+	store.fields = store.fields[:0]
+	store.values = store.values[:0]
+	store.prepareStr.Reset()
+	store.execs = store.execs[:0]
+}
+
+// Clear 重置 limit 缓存
+func (store *StoreLimit) Clear() {
+	store.offset = 0
+	store.count = 0
+}
@@ -0,0 +1,524 @@
+// Package memdb 提供用于测试的内存数据库驱动，支持 ORM 生成的建表、增删改查语句及简单事务，
+// 导入后可通过 orm.NewEngine("memdb://name") 使用，相同名称共享同一数据库
+package memdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cquestor/cc/orm"
+)
+
+// DriverName 驱动名称
+const DriverName = "memdb"
+
+// Driver 内存数据库驱动
+type Driver struct{}
+
+// Dialect 内存数据库方言，语法同 SQLite
+var Dialect orm.IDialect = dialect{orm.SQLite}
+
+// database 内存数据库
+type database struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// table 数据表
+type table struct {
+	columns []string
+	auto    string
+	nextID  int64
+	rows    [][]driver.Value
+}
+
+// databases 按名称共享的数据库
+var databases = struct {
+	sync.Mutex
+	named map[string]*database
+}{named: make(map[string]*database)}
+
+func init() {
+	sql.Register(DriverName, Driver{})
+	orm.RegisterDialect(DriverName, Dialect)
+}
+
+// Reset 清空指定名称的数据库
+func Reset(name string) {
+	databases.Lock()
+	defer databases.Unlock()
+	delete(databases.named, name)
+}
+
+// Open 实现 driver.Driver 接口，name 为数据库名称
+func (Driver) Open(name string) (driver.Conn, error) {
+	databases.Lock()
+	defer databases.Unlock()
+	db, ok := databases.named[name]
+	if !ok {
+		db = &database{tables: make(map[string]*table)}
+		databases.named[name] = db
+	}
+	return &conn{db: db}, nil
+}
+
+// dialect 内存数据库方言
+type dialect struct {
+	orm.IDialect
+}
+
+func (dialect) Name() string       { return DriverName }
+func (dialect) DriverName() string { return DriverName }
+
+func (dialect) DataSource(dsn string) string {
+	return strings.TrimPrefix(dsn, DriverName+"://")
+}
+
+// conn 数据库连接
+type conn struct {
+	db       *database
+	snapshot map[string]*table
+}
+
+// Prepare 实现 driver.Conn 接口
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	statement, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, statement: statement}, nil
+}
+
+// Close 实现 driver.Conn 接口
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin 实现 driver.Conn 接口
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 开启事务，回滚时恢复到开启时的状态；事务间不隔离
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.snapshot != nil {
+		return nil, fmt.Errorf("memdb: transaction already started")
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.snapshot = c.db.clone()
+	return &tx{conn: c}, nil
+}
+
+// tx 事务
+type tx struct {
+	conn *conn
+}
+
+// Commit 实现 driver.Tx 接口
+func (t *tx) Commit() error {
+	t.conn.snapshot = nil
+	return nil
+}
+
+// Rollback 实现 driver.Tx 接口
+func (t *tx) Rollback() error {
+	t.conn.db.mu.Lock()
+	defer t.conn.db.mu.Unlock()
+	if t.conn.snapshot != nil {
+		t.conn.db.tables = t.conn.snapshot
+		t.conn.snapshot = nil
+	}
+	return nil
+}
+
+// clone 复制全部数据表
+func (db *database) clone() map[string]*table {
+	tables := make(map[string]*table, len(db.tables))
+	for name, t := range db.tables {
+		copied := *t
+		copied.rows = make([][]driver.Value, len(t.rows))
+		for i, row := range t.rows {
+			copied.rows[i] = append([]driver.Value(nil), row...)
+		}
+		tables[name] = &copied
+	}
+	return tables
+}
+
+// stmt 预编译语句
+type stmt struct {
+	conn      *conn
+	statement *statement
+}
+
+// Close 实现 driver.Stmt 接口
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput 实现 driver.Stmt 接口
+func (s *stmt) NumInput() int {
+	return s.statement.inputs
+}
+
+// Exec 实现 driver.Stmt 接口
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.db.mu.Lock()
+	defer s.conn.db.mu.Unlock()
+	return s.statement.exec(s.conn.db, args)
+}
+
+// Query 实现 driver.Stmt 接口
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.db.mu.Lock()
+	defer s.conn.db.mu.Unlock()
+	return s.statement.query(s.conn.db, args)
+}
+
+// result 执行结果
+type result struct {
+	lastID   int64
+	affected int64
+}
+
+// LastInsertId 实现 driver.Result 接口
+func (r result) LastInsertId() (int64, error) {
+	return r.lastID, nil
+}
+
+// RowsAffected 实现 driver.Result 接口
+func (r result) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// rows 查询结果
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// Columns 实现 driver.Rows 接口
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+// Close 实现 driver.Rows 接口
+func (r *rows) Close() error {
+	return nil
+}
+
+// Next 实现 driver.Rows 接口
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// exec 执行建表、插入、更新及删除语句
+func (s *statement) exec(db *database, args []driver.Value) (driver.Result, error) {
+	if s.kind == "CREATE" {
+		if _, ok := db.tables[s.table]; ok {
+			if s.ifNotExists {
+				return result{}, nil
+			}
+			return nil, fmt.Errorf("memdb: table %s already exists", s.table)
+		}
+		db.tables[s.table] = &table{columns: s.columns, auto: s.auto}
+		return result{}, nil
+	}
+	t, ok := db.tables[s.table]
+	if !ok {
+		return nil, fmt.Errorf("memdb: no such table: %s", s.table)
+	}
+	switch s.kind {
+	case "INSERT":
+		return t.insert(s, args)
+	case "UPDATE":
+		var affected int64
+		for _, row := range t.rows {
+			match, err := t.match(row, s.where, args)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+			for _, set := range s.sets {
+				i, err := t.column(set.column)
+				if err != nil {
+					return nil, err
+				}
+				row[i] = set.value.resolve(args)
+			}
+			affected++
+		}
+		return result{affected: affected}, nil
+	case "DELETE":
+		kept := t.rows[:0]
+		var affected int64
+		for _, row := range t.rows {
+			match, err := t.match(row, s.where, args)
+			if err != nil {
+				return nil, err
+			}
+			if match {
+				affected++
+			} else {
+				kept = append(kept, row)
+			}
+		}
+		t.rows = kept
+		return result{affected: affected}, nil
+	}
+	return nil, fmt.Errorf("memdb: %s cannot be executed", s.kind)
+}
+
+// insert 插入数据，自增列未指定时自动生成
+func (t *table) insert(s *statement, args []driver.Value) (driver.Result, error) {
+	indexes := make([]int, len(s.columns))
+	for i, name := range s.columns {
+		index, err := t.column(name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+	}
+	var res result
+	for _, values := range s.values {
+		if len(values) != len(s.columns) {
+			return nil, fmt.Errorf("memdb: %d values for %d columns", len(values), len(s.columns))
+		}
+		row := make([]driver.Value, len(t.columns))
+		for i, value := range values {
+			row[indexes[i]] = value.resolve(args)
+		}
+		if t.auto != "" {
+			i, _ := t.column(t.auto)
+			if id, ok := row[i].(int64); ok && id != 0 {
+				if id > t.nextID {
+					t.nextID = id
+				}
+			} else {
+				t.nextID++
+				row[i] = t.nextID
+			}
+			res.lastID = row[i].(int64)
+		}
+		t.rows = append(t.rows, row)
+		res.affected++
+	}
+	return res, nil
+}
+
+// query 执行查询语句
+func (s *statement) query(db *database, args []driver.Value) (driver.Rows, error) {
+	if s.kind != "SELECT" {
+		return nil, fmt.Errorf("memdb: %s cannot be queried", s.kind)
+	}
+	t, ok := db.tables[s.table]
+	if !ok {
+		return nil, fmt.Errorf("memdb: no such table: %s", s.table)
+	}
+	columns := s.columns
+	if len(columns) == 1 && columns[0] == "*" {
+		columns = t.columns
+	}
+	indexes := make([]int, len(columns))
+	for i, name := range columns {
+		index, err := t.column(name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+	}
+	matched := make([][]driver.Value, 0)
+	for _, row := range t.rows {
+		match, err := t.match(row, s.where, args)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			matched = append(matched, row)
+		}
+	}
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		index, err := t.column(s.orders[i].column)
+		if err != nil {
+			return nil, err
+		}
+		desc := s.orders[i].desc
+		sort.SliceStable(matched, func(a, b int) bool {
+			if desc {
+				return compare(matched[b][index], matched[a][index]) < 0
+			}
+			return compare(matched[a][index], matched[b][index]) < 0
+		})
+	}
+	if s.offset != nil {
+		offset := int(toInt(s.offset.resolve(args)))
+		if offset > len(matched) {
+			offset = len(matched)
+		}
+		matched = matched[offset:]
+	}
+	if s.limit != nil {
+		if limit := int(toInt(s.limit.resolve(args))); limit >= 0 && limit < len(matched) {
+			matched = matched[:limit]
+		}
+	}
+	result := &rows{columns: columns, values: make([][]driver.Value, len(matched))}
+	for i, row := range matched {
+		values := make([]driver.Value, len(indexes))
+		for j, index := range indexes {
+			values[j] = row[index]
+		}
+		result.values[i] = values
+	}
+	return result, nil
+}
+
+// column 列序号
+func (t *table) column(name string) (int, error) {
+	for i, column := range t.columns {
+		if strings.EqualFold(column, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("memdb: no such column: %s", name)
+}
+
+// match 行是否满足全部条件
+func (t *table) match(row []driver.Value, where []condition, args []driver.Value) (bool, error) {
+	for _, cond := range where {
+		i, err := t.column(cond.column)
+		if err != nil {
+			return false, err
+		}
+		value := cond.value.resolve(args)
+		if row[i] == nil || value == nil {
+			return false, nil
+		}
+		if cond.op == "LIKE" {
+			if !like(string(toBytes(row[i])), string(toBytes(value))) {
+				return false, nil
+			}
+			continue
+		}
+		c := compare(row[i], value)
+		var ok bool
+		switch cond.op {
+		case "=":
+			ok = c == 0
+		case "!=", "<>":
+			ok = c != 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compare 比较两个值，数值按大小、时间按先后、其余按字符串比较
+func compare(a, b driver.Value) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return bytes.Compare(toBytes(a), toBytes(b))
+}
+
+// like LIKE 匹配，% 匹配任意个字符，_ 匹配单个字符，不区分大小写
+func like(s, pattern string) bool {
+	text, expr := []rune(strings.ToLower(s)), []rune(strings.ToLower(pattern))
+	if len(expr) == 0 {
+		return len(text) == 0
+	}
+	switch expr[0] {
+	case '%':
+		for i := 0; i <= len(text); i++ {
+			if like(string(text[i:]), string(expr[1:])) {
+				return true
+			}
+		}
+		return false
+	case '_':
+		return len(text) > 0 && like(string(text[1:]), string(expr[1:]))
+	}
+	return len(text) > 0 && text[0] == expr[0] && like(string(text[1:]), string(expr[1:]))
+}
+
+// toFloat 数值转换
+func toFloat(v driver.Value) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// toInt 整数转换
+func toInt(v driver.Value) int64 {
+	f, _ := toFloat(v)
+	return int64(f)
+}
+
+// toBytes 字符串表示
+func toBytes(v driver.Value) []byte {
+	switch v := v.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return []byte(fmt.Sprint(v))
+}
@@ -0,0 +1,453 @@
+package memdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// statement 解析后的语句
+type statement struct {
+	kind        string
+	table       string
+	ifNotExists bool
+	auto        string
+	columns     []string
+	values      [][]value
+	sets        []assignment
+	where       []condition
+	orders      []order
+	limit       *value
+	offset      *value
+	inputs      int
+}
+
+// value 字面量或参数
+type value struct {
+	literal driver.Value
+	arg     int
+}
+
+// assignment set 子句
+type assignment struct {
+	column string
+	value  value
+}
+
+// condition where 条件
+type condition struct {
+	column string
+	op     string
+	value  value
+}
+
+// order 排序
+type order struct {
+	column string
+	desc   bool
+}
+
+// resolve 取值，参数从 1 开始编号
+func (v value) resolve(args []driver.Value) driver.Value {
+	if v.arg > 0 && v.arg <= len(args) {
+		return args[v.arg-1]
+	}
+	return v.literal
+}
+
+// token 词法单元
+type token struct {
+	text   string
+	quoted bool
+	str    bool
+}
+
+// parser 语法分析器
+type parser struct {
+	tokens []token
+	pos    int
+	stmt   *statement
+}
+
+// parse 解析 ORM 生成的语句
+func parse(query string) (*statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, stmt: &statement{}}
+	switch strings.ToUpper(p.next().text) {
+	case "CREATE":
+		err = p.create()
+	case "INSERT":
+		err = p.insert()
+	case "UPDATE":
+		err = p.update()
+	case "DELETE":
+		err = p.delete()
+	case "SELECT":
+		err = p.selects()
+	default:
+		err = fmt.Errorf("memdb: unsupported statement: %s", query)
+	}
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("memdb: unexpected %q in: %s", p.tokens[p.pos].text, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.stmt, nil
+}
+
+// tokenize 词法分析
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '`' || c == '"' || c == '\'':
+			var builder strings.Builder
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] == c {
+					if j+1 < len(query) && query[j+1] == c {
+						builder.WriteByte(c)
+						j++
+						continue
+					}
+					break
+				}
+				builder.WriteByte(query[j])
+			}
+			if j >= len(query) {
+				return nil, fmt.Errorf("memdb: unterminated quote in: %s", query)
+			}
+			tokens = append(tokens, token{text: builder.String(), quoted: c != '\'', str: c == '\''})
+			i = j + 1
+		case strings.IndexByte("(),*?", c) >= 0:
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case strings.IndexByte("=!<>", c) >= 0:
+			j := i + 1
+			for j < len(query) && strings.IndexByte("=<>", query[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, token{text: query[i:j]})
+			i = j
+		default:
+			j := i
+			for j < len(query) && strings.IndexByte(" \t\n\r(),*?=!<>`\"'", query[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, token{text: query[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// next 读取下一个词法单元
+func (p *parser) next() token {
+	if p.pos >= len(p.tokens) {
+		return token{}
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+// peek 下一个词法单元是否为指定关键字
+func (p *parser) peek(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && !p.tokens[p.pos].str &&
+		strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+// accept 下一个词法单元为指定关键字时读取
+func (p *parser) accept(keyword string) bool {
+	if p.peek(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect 读取指定关键字
+func (p *parser) expect(keywords ...string) error {
+	for _, keyword := range keywords {
+		if !p.accept(keyword) {
+			return fmt.Errorf("memdb: expected %s", keyword)
+		}
+	}
+	return nil
+}
+
+// identifier 读取标识符
+func (p *parser) identifier() (string, error) {
+	t := p.next()
+	if t.text == "" || t.str {
+		return "", fmt.Errorf("memdb: expected identifier")
+	}
+	return t.text, nil
+}
+
+// value 读取字面量或参数
+func (p *parser) value() (value, error) {
+	t := p.next()
+	switch {
+	case t.str:
+		return value{literal: t.text}, nil
+	case t.quoted || t.text == "":
+		return value{}, fmt.Errorf("memdb: expected value")
+	case t.text == "?":
+		p.stmt.inputs++
+		return value{arg: p.stmt.inputs}, nil
+	case t.text[0] == '$':
+		n, err := strconv.Atoi(t.text[1:])
+		if err != nil {
+			return value{}, fmt.Errorf("memdb: invalid placeholder %s", t.text)
+		}
+		if n > p.stmt.inputs {
+			p.stmt.inputs = n
+		}
+		return value{arg: n}, nil
+	case strings.EqualFold(t.text, "NULL"):
+		return value{}, nil
+	}
+	if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+		return value{literal: n}, nil
+	}
+	if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+		return value{literal: f}, nil
+	}
+	return value{}, fmt.Errorf("memdb: invalid value %s", t.text)
+}
+
+// create CREATE TABLE [IF NOT EXISTS] t (column type ..., ...)
+func (p *parser) create() (err error) {
+	p.stmt.kind = "CREATE"
+	if err := p.expect("TABLE"); err != nil {
+		return err
+	}
+	if p.accept("IF") {
+		if err := p.expect("NOT", "EXISTS"); err != nil {
+			return err
+		}
+		p.stmt.ifNotExists = true
+	}
+	if p.stmt.table, err = p.identifier(); err != nil {
+		return err
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		column, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		p.stmt.columns = append(p.stmt.columns, column)
+		for depth := 0; depth > 0 || !(p.peek(",") || p.peek(")")); {
+			t := p.next()
+			switch {
+			case t.text == "":
+				return fmt.Errorf("memdb: unterminated column list")
+			case t.text == "(":
+				depth++
+			case t.text == ")":
+				depth--
+			case strings.EqualFold(t.text, "AUTO_INCREMENT"), strings.EqualFold(t.text, "AUTOINCREMENT"),
+				strings.EqualFold(t.text, "SERIAL"), strings.EqualFold(t.text, "BIGSERIAL"):
+				p.stmt.auto = column
+			}
+		}
+		if !p.accept(",") {
+			return p.expect(")")
+		}
+	}
+}
+
+// insert INSERT INTO t (columns) VALUES (values), ...
+func (p *parser) insert() (err error) {
+	p.stmt.kind = "INSERT"
+	if err := p.expect("INTO"); err != nil {
+		return err
+	}
+	if p.stmt.table, err = p.identifier(); err != nil {
+		return err
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		column, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		p.stmt.columns = append(p.stmt.columns, column)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")", "VALUES"); err != nil {
+		return err
+	}
+	for {
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		var values []value
+		for {
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		p.stmt.values = append(p.stmt.values, values)
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+// update UPDATE t SET column = value, ... [WHERE ...]
+func (p *parser) update() (err error) {
+	p.stmt.kind = "UPDATE"
+	if p.stmt.table, err = p.identifier(); err != nil {
+		return err
+	}
+	if err := p.expect("SET"); err != nil {
+		return err
+	}
+	for {
+		column, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		v, err := p.value()
+		if err != nil {
+			return err
+		}
+		p.stmt.sets = append(p.stmt.sets, assignment{column: column, value: v})
+		if !p.accept(",") {
+			break
+		}
+	}
+	return p.where()
+}
+
+// delete DELETE FROM t [WHERE ...]
+func (p *parser) delete() (err error) {
+	p.stmt.kind = "DELETE"
+	if err := p.expect("FROM"); err != nil {
+		return err
+	}
+	if p.stmt.table, err = p.identifier(); err != nil {
+		return err
+	}
+	return p.where()
+}
+
+// selects SELECT columns FROM t [WHERE ...] [ORDER BY ...] [LIMIT ...]
+func (p *parser) selects() (err error) {
+	p.stmt.kind = "SELECT"
+	for {
+		column, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		p.stmt.columns = append(p.stmt.columns, column)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect("FROM"); err != nil {
+		return err
+	}
+	if p.stmt.table, err = p.identifier(); err != nil {
+		return err
+	}
+	if err := p.where(); err != nil {
+		return err
+	}
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return err
+		}
+		for {
+			column, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			desc := p.accept("DESC")
+			if !desc {
+				p.accept("ASC")
+			}
+			p.stmt.orders = append(p.stmt.orders, order{column: column, desc: desc})
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("LIMIT") {
+		limit, err := p.value()
+		if err != nil {
+			return err
+		}
+		switch {
+		case p.accept(","):
+			offset, err := p.value()
+			if err != nil {
+				return err
+			}
+			p.stmt.limit, p.stmt.offset = &offset, &limit
+		case p.accept("OFFSET"):
+			offset, err := p.value()
+			if err != nil {
+				return err
+			}
+			p.stmt.limit, p.stmt.offset = &limit, &offset
+		default:
+			p.stmt.limit = &limit
+		}
+	}
+	return nil
+}
+
+// where WHERE column op value AND ...
+func (p *parser) where() error {
+	if !p.accept("WHERE") {
+		return nil
+	}
+	for {
+		column, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		op := strings.ToUpper(p.next().text)
+		switch op {
+		case "=", "!=", "<>", "<", "<=", ">", ">=", "LIKE":
+		default:
+			return fmt.Errorf("memdb: unsupported operator %q", op)
+		}
+		v, err := p.value()
+		if err != nil {
+			return err
+		}
+		p.stmt.where = append(p.stmt.where, condition{column: column, op: op, value: v})
+		if !p.accept("AND") {
+			return nil
+		}
+	}
+}
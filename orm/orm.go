@@ -2,27 +2,31 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // Engine ORM引擎
 type Engine struct {
-	DB *sql.DB
+	DB      *sql.DB
+	dialect IDialect
+	hooks   []IHook
+}
+
+// IHook 查询钩子，可用于记录耗时、链路追踪等
+type IHook interface {
+	BeforeQuery(ctx context.Context, query string, args []any) context.Context
+	AfterQuery(ctx context.Context, query string, err error)
 }
 
 // Session 数据库会话
 type Session struct {
-	db          *sql.DB
-	table       []string
-	sql         *strings.Builder
-	storeInsert *StoreInsert
-	storeWhere  []*StoreWhere
-	storeSet    []*StoreSet
-	storeLimit  *StoreLimit
-	storeOrder  []*StoreOrder
+	builder[*Session]
+	db *sql.DB
 }
 
 // StoreInsert 插入缓存
@@ -58,34 +62,104 @@ type StoreOrder struct {
 	desc bool
 }
 
-// NewEngine 构造ORM引擎
-func NewEngine(source string) (*Engine, error) {
-	db, err := connect(source)
-	if err != nil {
+type (
+	// CPool 连接池配置，取值含义同 database/sql，连接建立前生效
+	CPool struct {
+		MaxOpenConns    int           // 最大打开连接数，0 表示不限制
+		MaxIdleConns    int           // 最大空闲连接数，0 表示不保留空闲连接
+		ConnMaxLifetime time.Duration // 连接最长存活时间，0 表示不限制
+		ConnMaxIdleTime time.Duration // 连接最长空闲时间，0 表示不限制
+	}
+	// CRetry 启动时连接重试，等待时间按指数退避增长
+	CRetry struct {
+		Attempts   int                          // 首次失败后的重试次数
+		Backoff    time.Duration                // 首次重试前的等待时间，默认 500ms
+		MaxBackoff time.Duration                // 最长等待时间，默认 30s
+		OnRetry    func(attempt int, err error) // 每次重试前调用，可用于记录日志
+	}
+	// CDriver 驱动名称，覆盖方言默认的驱动，如 PostgreSQL 使用 pgx 驱动时传入 "pgx"
+	CDriver string
+	// CDB 已打开的数据库连接，传入后不再依据 DSN 打开连接，构造失败时不会关闭；方言默认为 MySQL，可通过 IDialect 选项指定
+	CDB *sql.DB
+)
+
+// NewEngine 构造ORM引擎，依据 DSN scheme 选择方言，可传入 CPool、CRetry、CDriver、CDB 选项，
+// 传入 IDialect 时覆盖 DSN 选择的方言；驱动需由调用方导入注册，如 _ "github.com/go-sql-driver/mysql"
+func NewEngine(source string, options ...any) (*Engine, error) {
+	return NewEngineContext(context.Background(), source, options...)
+}
+
+// NewEngineContext 同 NewEngine，ctx 结束时停止连接检查及重试等待
+func NewEngineContext(ctx context.Context, source string, options ...any) (*Engine, error) {
+	var pool *CPool
+	var retry CRetry
+	var driverName string
+	var db *sql.DB
+	var dialect IDialect
+	for _, option := range options {
+		switch option := option.(type) {
+		case CPool:
+			pool = &option
+		case CRetry:
+			retry = option
+		case CDriver:
+			driverName = string(option)
+		case CDB:
+			db = option
+		case IDialect:
+			dialect = option
+		}
+	}
+	if db == nil || source != "" {
+		parsed, dataSource, err := ParseDSN(source)
+		if err != nil {
+			return nil, err
+		}
+		if dialect == nil {
+			dialect = parsed
+		}
+		source = dataSource
+	}
+	if dialect == nil {
+		dialect = MySQL
+	}
+	owned := db == nil
+	if owned {
+		if driverName == "" {
+			driverName = dialect.DriverName()
+		}
+		if err := checkDriver(driverName); err != nil {
+			return nil, err
+		}
+		var err error
+		if db, err = sql.Open(driverName, source); err != nil {
+			return nil, err
+		}
+	}
+	engine := &Engine{DB: db, dialect: dialect}
+	if pool != nil {
+		engine.SetPool(*pool)
+	}
+	if err := ping(ctx, db, retry); err != nil {
+		// 通过 CDB 传入的连接由调用方管理，不在此关闭
+		if owned {
+			db.Close()
+		}
 		return nil, err
 	}
-	return &Engine{
-		DB: db,
-	}, nil
+	return engine, nil
 }
 
 // NewSession 构造数据库会话
 func (engine *Engine) NewSession() *Session {
-	return &Session{
-		db:    engine.DB,
-		table: make([]string, 0),
-		sql:   &strings.Builder{},
-		storeInsert: &StoreInsert{
-			fields:     make([]string, 0),
-			values:     make([][]any, 0),
-			prepareStr: &strings.Builder{},
-			execs:      make([]any, 0),
-		},
-		storeWhere: make([]*StoreWhere, 0),
-		storeSet:   make([]*StoreSet, 0),
-		storeLimit: &StoreLimit{},
-		storeOrder: make([]*StoreOrder, 0),
-	}
+	session := &Session{db: engine.DB}
+	session.builder = newBuilder(session, engine.DB, engine.dialect, context.Background(), engine.hooks)
+	return session
+}
+
+// Dialect 获取 SQL 方言
+func (engine *Engine) Dialect() IDialect {
+	return engine.dialect
 }
 
 // SetMaxOpenConns 设置打开的最大连接数
@@ -98,275 +172,67 @@ func (engine *Engine) SetMaxIdleConns(v int) {
 	engine.DB.SetMaxIdleConns(v)
 }
 
-// Close 关闭数据库连接
-func (engine *Engine) Close() {
-	engine.DB.Close()
-}
-
-// GetTx 获取事务
-func (session *Session) Begin() (*CTx, error) {
-	tx, err := session.db.Begin()
-	if err != nil {
-		return nil, err
-	}
-	return &CTx{
-		tx:    tx,
-		table: make([]string, 0),
-		sql:   &strings.Builder{},
-		storeInsert: &StoreInsert{
-			fields:     make([]string, 0),
-			values:     make([][]any, 0),
-			prepareStr: &strings.Builder{},
-			execs:      make([]any, 0),
-		},
-		storeWhere: make([]*StoreWhere, 0),
-		storeSet:   make([]*StoreSet, 0),
-		storeLimit: &StoreLimit{},
-		storeOrder: make([]*StoreOrder, 0),
-	}, nil
-}
-
-// Table 设置表格名
-func (session *Session) Table(name string) *Session {
-	session.table = append(session.table, "`"+name+"`")
-	return session
-}
-
-// Where 添加 Where 子句
-func (session *Session) Where(field, flag string, v any) *Session {
-	session.storeWhere = append(session.storeWhere, &StoreWhere{prepareStr: fmt.Sprintf("%s %s ?", field, flag), exec: v})
-	return session
-}
-
-// Equal 相等 Where 子句
-func (session *Session) Equal(field string, v any) *Session {
-	return session.Where(field, "=", v)
-}
-
-// Unequal 不相等 Where 子句
-func (session *Session) Unequal(field string, v any) *Session {
-	return session.Where(field, "!=", v)
-}
-
-// Set 添加 Set 子句
-func (session *Session) Set(field string, v any) *Session {
-	session.storeSet = append(session.storeSet, &StoreSet{prepareStr: fmt.Sprintf("%s = ?", field), exec: v})
-	return session
-}
-
-// Limit 添加 Limit 子句
-func (session *Session) Limit(count int, offset ...int) *Session {
-	if len(offset) < 1 {
-		offset = append(offset, 0)
-	}
-	session.storeLimit.offset = offset[0]
-	session.storeLimit.count = count
-	return session
-}
-
-// Order 添加 Order By 子句
-func (session *Session) Order(name string, desc ...bool) *Session {
-	if len(desc) < 1 {
-		desc = append(desc, false)
-	}
-	session.storeOrder = append(session.storeOrder, &StoreOrder{name: name, desc: desc[0]})
-	return session
+// SetConnMaxLifetime 设置连接最长存活时间，超过后连接在复用前关闭
+func (engine *Engine) SetConnMaxLifetime(d time.Duration) {
+	engine.DB.SetConnMaxLifetime(d)
 }
 
-// Update 修改数据
-func (session *Session) Update() error {
-	defer session.Reset()
-	if len(session.table) < 1 {
-		return fmt.Errorf("table name is empty, forgot set it?")
-	}
-	session.sql.WriteString(fmt.Sprintf("UPDATE %s", session.table[0]))
-	execs := make([]any, 0)
-	addSets(session.sql, session.storeSet, &execs)
-	addWheres(session.sql, session.storeWhere, &execs)
-	return session._exec(execs...)
+// SetConnMaxIdleTime 设置连接最长空闲时间，超过后空闲连接被关闭
+func (engine *Engine) SetConnMaxIdleTime(d time.Duration) {
+	engine.DB.SetConnMaxIdleTime(d)
 }
 
-// Delete 删除数据
-func (session *Session) Delete() error {
-	defer session.Reset()
-	if len(session.table) < 1 {
-		return fmt.Errorf("table name is empty, forgot set it?")
-	}
-	session.sql.WriteString(fmt.Sprintf("DELETE FROM %s", session.table[0]))
-	execs := make([]any, 0)
-	addWheres(session.sql, session.storeWhere, &execs)
-	return session._exec(execs...)
+// SetPool 设置连接池
+func (engine *Engine) SetPool(pool CPool) {
+	engine.SetMaxOpenConns(pool.MaxOpenConns)
+	engine.SetMaxIdleConns(pool.MaxIdleConns)
+	engine.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	engine.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 }
 
-// Insert 插入数据
-func (session *Session) Insert(v ...any) error {
-	defer session.Reset()
-	if len(session.table) < 1 {
-		return fmt.Errorf("table name is empty, forgot set it?")
-	}
-	for _, each := range v {
-		each, err := checkInsertObject(each)
-		if err != nil {
-			return err
-		}
-		fields, values, _type := parseInsertObject(each)
-		if err := session._insert(fields, values, _type); err != nil {
-			return err
-		}
-	}
-	session.sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", session.table[0], strings.Join(session.storeInsert.fields, ", "), session.storeInsert.prepareStr.String()))
-	return session._exec(session.storeInsert.execs...)
+// Stats 获取连接池统计信息
+func (engine *Engine) Stats() sql.DBStats {
+	return engine.DB.Stats()
 }
 
-// _insert 插入一条数据
-func (session *Session) _insert(fields []string, values []any, _type reflect.Type) error {
-	if session.storeInsert.elem == nil {
-		session.storeInsert.elem = _type
-	}
-	if session.storeInsert.elem != _type {
-		return fmt.Errorf("all inserted objects must be of the same type (%s) <=> (%s)", session.storeInsert.elem.Name(), _type.Name())
-	}
-	session.storeInsert.fields = fields
-	session.storeInsert.values = append(session.storeInsert.values, values)
-	session.storeInsert.execs = append(session.storeInsert.execs, values...)
-	if session.storeInsert.prepareStr.String() != "" {
-		session.storeInsert.prepareStr.WriteString(", ")
-	}
-	session.storeInsert.prepareStr.WriteString(fmt.Sprintf("(%s)", strings.Join(genPrepare(len(values)), ", ")))
-	return nil
+// AddHook 添加查询钩子，对之后创建的会话生效
+func (engine *Engine) AddHook(hooks ...IHook) {
+	engine.hooks = append(engine.hooks, hooks...)
 }
 
-// Select 查询数据
-func (session *Session) Select(v any) (int, error) {
-	defer session.Reset()
-	if len(session.table) < 1 {
-		return 0, fmt.Errorf("table name is empty, forgot set it?")
-	}
-	t := reflect.TypeOf(v)
-	if t.Kind() != reflect.Ptr {
-		return 0, fmt.Errorf("the select target object must be a ptr, not %s", t.Kind())
-	}
-	_type, isSlice, isPointer, err := checkSelectObject(v)
-	if err != nil {
-		return 0, err
-	}
-	if isSlice {
-		rows, err := session._select(v, _type)
-		if err != nil {
-			return 0, err
-		}
-		n, values, err := session._select_fetch(_type, rows)
-		if err != nil {
-			return 0, err
-		}
-		_value := reflect.ValueOf(v)
-		for _, each := range values {
-			if isPointer {
-				_value.Elem().Set(reflect.Append(_value.Elem(), each))
-			} else {
-				_value.Elem().Set(reflect.Append(_value.Elem(), each.Elem()))
-			}
-		}
-		return n, nil
-	}
-	session.Limit(1)
-	rows, err := session._select([]any{v}, _type)
-	if err != nil {
-		return 0, err
-	}
-	return session._select_one(v, rows)
+// Close 关闭数据库连接
+func (engine *Engine) Close() {
+	engine.DB.Close()
 }
 
-// _select 查询数据
-func (session *Session) _select(v any, t reflect.Type) (*sql.Rows, error) {
-	execs := make([]any, 0)
-	fields := parseSelectObject(t)
-	session.sql.WriteString(fmt.Sprintf("SELECT %s FROM %s", strings.Join(fields, ", "), strings.Join(session.table, ", ")))
-	addWheres(session.sql, session.storeWhere, &execs)
-	addOrders(session.sql, session.storeOrder, &execs)
-	addLimit(session.sql, session.storeLimit, &execs)
-	stmt, err := session.db.Prepare(session.sql.String())
-	if err != nil {
-		return nil, err
-	}
-	defer stmt.Close()
-	rows, err := stmt.Query(execs...)
+// Begin 开启事务，事务继承会话的上下文及查询钩子
+func (session *Session) Begin() (*CTx, error) {
+	tx, err := session.db.BeginTx(session.ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return rows, nil
+	ctx := &CTx{tx: tx}
+	ctx.builder = newBuilder(ctx, tx, session.dialect, session.ctx, session.hooks)
+	return ctx, nil
 }
 
-// _select_one 查询单条数据
-func (session *Session) _select_one(v any, rows *sql.Rows) (int, error) {
-	defer rows.Close()
-	if !rows.Next() {
-		return 0, nil
-	}
-	if err := scanOneObject(v, rows); err != nil {
-		return 0, err
+// Transaction 在事务中执行 fn，fn 返回 nil 时提交，返回错误或 panic 时回滚，panic 在回滚后重新抛出
+func (session *Session) Transaction(fn func(tx *CTx) error) (err error) {
+	tx, err := session.Begin()
+	if err != nil {
+		return err
 	}
-	return 1, nil
-}
-
-// _select_fetch 查询多条数据
-func (session *Session) _select_fetch(t reflect.Type, rows *sql.Rows) (int, []reflect.Value, error) {
-	defer rows.Close()
-	effectCount := 0
-	values := make([]reflect.Value, 0)
-	if !rows.Next() {
-		return 0, nil, nil
-	} else {
-		effectCount++
-		if value, err := scanFetchObject(t, rows); err != nil {
-			return 0, nil, err
-		} else {
-			values = append(values, value)
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
 		}
-		for rows.Next() {
-			effectCount++
-			if value, err := scanFetchObject(t, rows); err != nil {
-				return 0, nil, err
-			} else {
-				values = append(values, value)
-			}
+	}()
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
 		}
-	}
-	return effectCount, values, nil
-}
-
-// _exec 执行 sql 语句
-func (session *Session) _exec(execs ...any) error {
-	stmt, err := session.db.Prepare(session.sql.String())
-	if err != nil {
 		return err
 	}
-	defer stmt.Close()
-	_, err = stmt.Exec(execs...)
-	return err
-}
-
-// Reset 重置会话
-func (session *Session) Reset() {
-	session.table = session.table[:0]
-	session.storeInsert.Clear()
-	session.storeWhere = session.storeWhere[:0]
-	session.storeSet = session.storeSet[:0]
-	session.storeLimit.Clear()
-	session.sql.Reset()
-}
-
-// Clear 清除插入缓存
-func (store *StoreInsert) Clear() {
-	store.elem = nil
-	store.fields = store.fields[:0]
-	store.values = store.values[:0]
-	store.prepareStr.Reset()
-	store.execs = store.execs[:0]
-}
-
-// Clear 重置 limit 缓存
-func (store *StoreLimit) Clear() {
-	store.offset = 0
-	store.count = 0
+	return tx.Commit()
 }
@@ -2,6 +2,7 @@ package orm
 
 import (
 	"database/sql"
+	"fmt"
 	"reflect"
 	"strings"
 )
@@ -9,7 +10,7 @@ import (
 const myTag = "data"
 
 // parseInsertObject 解析插入对象
-func parseInsertObject(v any) ([]string, []any, reflect.Type) {
+func parseInsertObject(v any, dialect IDialect) ([]string, []any, reflect.Type) {
 	var fields []string
 	var values []any
 	t := reflect.TypeOf(v)
@@ -22,10 +23,10 @@ func parseInsertObject(v any) ([]string, []any, reflect.Type) {
 		if interested, tag := isInsertInterested(field.Tag.Get(myTag)); !interested {
 			continue
 		} else if tag != "" {
-			fields = append(fields, "`"+tag+"`")
+			fields = append(fields, dialect.Quote(tag))
 			values = append(values, e.Field(i).Interface())
 		} else {
-			fields = append(fields, "`"+field.Name+"`")
+			fields = append(fields, dialect.Quote(field.Name))
 			values = append(values, e.Field(i).Interface())
 		}
 	}
@@ -33,7 +34,7 @@ func parseInsertObject(v any) ([]string, []any, reflect.Type) {
 }
 
 // parseSelectObject 解析查询对象
-func parseSelectObject(t reflect.Type) []string {
+func parseSelectObject(t reflect.Type, dialect IDialect) []string {
 	var fields []string
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -43,9 +44,9 @@ func parseSelectObject(t reflect.Type) []string {
 		if interested, tag := isSelectInterested(field.Tag.Get(myTag)); !interested {
 			continue
 		} else if tag != "" {
-			fields = append(fields, "`"+tag+"`")
+			fields = append(fields, dialect.Quote(tag))
 		} else {
-			fields = append(fields, "`"+field.Name+"`")
+			fields = append(fields, dialect.Quote(field.Name))
 		}
 	}
 	return fields
@@ -120,3 +121,63 @@ func scanOneObject(v any, rows *sql.Rows) error {
 	}
 	return nil
 }
+
+// createTableSQL 依据结构体生成建表语句，auto_increment 字段作为自增主键，use_default 及 - 标记的字段同样建列
+func createTableSQL(table string, v any, dialect IDialect) (string, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("the table object must be a struct, not %v", t)
+	}
+	columns := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		parts := strings.Split(field.Tag.Get(myTag), ",")
+		name := parts[0]
+		if name == "" {
+			name = field.Name
+		}
+		autoIncrement := len(parts) > 1 && parts[1] == "auto_increment"
+		columnType := dialect.ColumnType(field.Type, autoIncrement)
+		if columnType == "" {
+			return "", fmt.Errorf("unsupported column type %s of field %s for %s", field.Type, field.Name, dialect.Name())
+		}
+		columns = append(columns, dialect.Quote(name)+" "+columnType)
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(columns, ", ")), nil
+}
+
+// autoIncrementField 单个结构体指针的自增字段及列名，用于插入后回填主键
+func autoIncrementField(v []any) (reflect.Value, string, bool) {
+	if len(v) != 1 {
+		return reflect.Value{}, "", false
+	}
+	value := reflect.ValueOf(v[0])
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, "", false
+	}
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		parts := strings.Split(field.Tag.Get(myTag), ",")
+		if !field.IsExported() || len(parts) < 2 || parts[1] != "auto_increment" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return reflect.Value{}, "", false
+		}
+		if parts[0] == "" {
+			parts[0] = field.Name
+		}
+		return value.Field(i), parts[0], true
+	}
+	return reflect.Value{}, "", false
+}
@@ -1,22 +1,183 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
-// connect 连接数据库
-func connect(source string) (*sql.DB, error) {
-	db, err := sql.Open("mysql", source)
+// driverImports 常用驱动的导入路径，用于提示
+var driverImports = map[string]string{
+	"mysql":    "github.com/go-sql-driver/mysql",
+	"postgres": "github.com/lib/pq",
+	"pgx":      "github.com/jackc/pgx/v5/stdlib",
+	"sqlite3":  "github.com/mattn/go-sqlite3",
+	"sqlite":   "modernc.org/sqlite",
+}
+
+// checkDriver 检查驱动是否已注册，未注册时提示需要导入的包
+func checkDriver(name string) error {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return nil
+		}
+	}
+	if path, ok := driverImports[name]; ok {
+		return fmt.Errorf("sql driver %q is not registered, import it with _ %q or pass CDriver/CDB", name, path)
+	}
+	return fmt.Errorf("sql driver %q is not registered, import a driver package that registers it or pass CDriver/CDB", name)
+}
+
+// ping 检查数据库连接，失败时按退避策略重试，ctx 结束时立即返回
+func ping(ctx context.Context, db *sql.DB, retry CRetry) error {
+	backoff, maxBackoff := retry.Backoff, retry.MaxBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= retry.Attempts {
+			if attempt > 0 {
+				return fmt.Errorf("ping database after %d retries: %w", attempt, err)
+			}
+			return err
+		}
+		if retry.OnRetry != nil {
+			retry.OnRetry(attempt+1, err)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("ping database: %w", errors.Join(ctx.Err(), err))
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// executor 执行语句的数据库连接，*sql.DB 及 *sql.Tx 均满足
+type executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// queryRows 预编译并查询
+func queryRows(ctx context.Context, db executor, query string, args []any) (*sql.Rows, error) {
+	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	if err := db.Ping(); err != nil {
+	defer stmt.Close()
+	return stmt.QueryContext(ctx, args...)
+}
+
+// execStmt 预编译并执行
+func execStmt(ctx context.Context, db executor, query string, args []any) (sql.Result, error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	return stmt.ExecContext(ctx, args...)
+}
+
+// queryContext 转换占位符后查询，并执行查询钩子
+func queryContext(ctx context.Context, db executor, hooks []IHook, dialect IDialect, query string, args []any) (*sql.Rows, error) {
+	query = Rebind(dialect, query)
+	ctx = beforeQuery(ctx, hooks, query, args)
+	rows, err := queryRows(ctx, db, query, args)
+	afterQuery(ctx, hooks, query, err)
+	return rows, err
+}
+
+// execContext 转换占位符后执行，并执行查询钩子
+func execContext(ctx context.Context, db executor, hooks []IHook, dialect IDialect, query string, args []any) (sql.Result, error) {
+	query = Rebind(dialect, query)
+	ctx = beforeQuery(ctx, hooks, query, args)
+	result, err := execStmt(ctx, db, query, args)
+	afterQuery(ctx, hooks, query, err)
+	return result, err
+}
+
+// insertContext 执行插入语句，插入单个结构体指针时回填自增主键：
+// 方言支持 RETURNING 时通过查询取回，否则使用 LastInsertId
+func insertContext(ctx context.Context, db executor, hooks []IHook, dialect IDialect, query string, args []any, v []any) (sql.Result, error) {
+	field, column, ok := autoIncrementField(v)
+	if !ok {
+		return execContext(ctx, db, hooks, dialect, query, args)
+	}
+	if returning := dialect.Returning(column); returning != "" {
+		rows, err := queryContext(ctx, db, hooks, dialect, query+returning, args)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var id int64
+		if rows.Next() {
+			if err := rows.Scan(&id); err != nil {
+				return nil, err
+			}
+			setID(field, id)
+		}
+		return returningResult(id), rows.Err()
+	}
+	result, err := execContext(ctx, db, hooks, dialect, query, args)
+	if err != nil {
 		return nil, err
 	}
-	return db, nil
+	if id, err := result.LastInsertId(); err == nil {
+		setID(field, id)
+	}
+	return result, nil
+}
+
+// returningResult 通过 RETURNING 插入单行的执行结果
+type returningResult int64
+
+// LastInsertId 实现 sql.Result 接口
+func (result returningResult) LastInsertId() (int64, error) {
+	return int64(result), nil
+}
+
+// RowsAffected 实现 sql.Result 接口
+func (result returningResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+// setID 回填自增主键
+func setID(field reflect.Value, id int64) {
+	if field.CanInt() {
+		field.SetInt(id)
+	} else {
+		field.SetUint(uint64(id))
+	}
+}
+
+// beforeQuery 执行查询前钩子
+func beforeQuery(ctx context.Context, hooks []IHook, query string, args []any) context.Context {
+	for _, hook := range hooks {
+		ctx = hook.BeforeQuery(ctx, query, args)
+	}
+	return ctx
+}
+
+// afterQuery 按相反顺序执行查询后钩子
+func afterQuery(ctx context.Context, hooks []IHook, query string, err error) {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, query, err)
+	}
 }
 
 // genPrepare 生成占位符
@@ -57,11 +218,11 @@ func addSets(sql *strings.Builder, sets []*StoreSet, execs *[]any) {
 }
 
 // addLimit 添加 limit 语句
-func addLimit(sql *strings.Builder, limit *StoreLimit, execs *[]any) {
+func addLimit(sql *strings.Builder, limit *StoreLimit, execs *[]any, dialect IDialect) {
 	if limit.count > 0 {
-		sql.WriteString(" LIMIT ?, ?")
-		*execs = append(*execs, limit.offset)
-		*execs = append(*execs, limit.count)
+		clause, args := dialect.Limit(limit.count, limit.offset)
+		sql.WriteString(clause)
+		*execs = append(*execs, args...)
 	}
 }
 
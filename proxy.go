@@ -0,0 +1,247 @@
+package cc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// SetTrustedProxies 设置可信代理，支持 CIDR 及单个 IP，只有来自可信代理的转发头才会被采信
+func (engine *Engine) SetTrustedProxies(proxies ...string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		prefix, err := parseProxy(proxy)
+		if err != nil {
+			return err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	engine.proxies.Store(&prefixes)
+	return nil
+}
+
+// isTrustedProxy 判断地址是否为可信代理
+func (engine *Engine) isTrustedProxy(addr netip.Addr) bool {
+	if engine == nil || !addr.IsValid() {
+		return false
+	}
+	proxies := engine.proxies.Load()
+	if proxies == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range *proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 获取客户端 IP，仅当直连对端为可信代理时解析 Forwarded、X-Forwarded-For 及 X-Real-IP
+func (ctx *Context) ClientIP() string {
+	peer := peerAddr(ctx.Req)
+	if !ctx.engine.isTrustedProxy(peer) {
+		if peer.IsValid() {
+			return peer.String()
+		}
+		return ctx.Req.RemoteAddr
+	}
+	hops := forwardedFor(ctx.Req.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			break
+		}
+		addr = addr.Unmap()
+		if !ctx.engine.isTrustedProxy(addr) || i == 0 {
+			return addr.String()
+		}
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(ctx.Req.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+	return peer.String()
+}
+
+// Scheme 获取请求协议 http/https，仅当直连对端为可信代理时解析转发头，
+// 取值来自最外层可信代理添加的部分，客户端伪造的前置值不会被采信
+func (ctx *Context) Scheme() string {
+	if ctx.engine.isTrustedProxy(peerAddr(ctx.Req)) {
+		if proto := ctx.engine.forwardedParam(ctx.Req.Header, "proto"); proto != "" {
+			return strings.ToLower(proto)
+		}
+		if proto := ctx.engine.forwardedValue(ctx.Req.Header, "X-Forwarded-Proto"); proto != "" {
+			return strings.ToLower(proto)
+		}
+	}
+	if ctx.Req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host 获取请求主机，仅当直连对端为可信代理时解析转发头，取值方式同 Scheme
+func (ctx *Context) Host() string {
+	if ctx.engine.isTrustedProxy(peerAddr(ctx.Req)) {
+		if host := ctx.engine.forwardedParam(ctx.Req.Header, "host"); host != "" {
+			return host
+		}
+		if host := ctx.engine.forwardedValue(ctx.Req.Header, "X-Forwarded-Host"); host != "" {
+			return host
+		}
+	}
+	return ctx.Req.Host
+}
+
+// parseProxy 解析可信代理配置
+func parseProxy(v string) (netip.Prefix, error) {
+	v = strings.TrimSpace(v)
+	if strings.Contains(v, "/") {
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %v", v, err)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(v)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %v", v, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// peerAddr 获取直连对端地址
+func peerAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// forwardedFor 按由远及近的顺序获取转发链，优先使用 RFC 7239 Forwarded
+func forwardedFor(header http.Header) []string {
+	hops := make([]string, 0)
+	if values := header.Values("Forwarded"); len(values) > 0 {
+		for _, element := range parseForwarded(values) {
+			if node, ok := element["for"]; ok {
+				hops = append(hops, stripPort(node))
+			}
+		}
+		return hops
+	}
+	for _, each := range splitList(header.Values("X-Forwarded-For")) {
+		hops = append(hops, stripPort(each))
+	}
+	return hops
+}
+
+// forwardedParam 获取 Forwarded 中的参数：自右向左跳过可信代理转发的元素，
+// 自最外层可信代理添加的元素起取首个非空值
+func (engine *Engine) forwardedParam(header http.Header, key string) string {
+	elements := parseForwarded(header.Values("Forwarded"))
+	if len(elements) < 1 {
+		return ""
+	}
+	edge := len(elements) - 1
+	for edge > 0 && engine.isTrustedNode(elements[edge]["for"]) {
+		edge--
+	}
+	for _, element := range elements[edge:] {
+		if value := element[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// forwardedValue 获取 X-Forwarded-Proto 等逐跳追加的请求头中由最外层可信代理添加的值，
+// 可信代理跳数为直连对端加上 X-Forwarded-For 末尾连续的可信地址数
+func (engine *Engine) forwardedValue(header http.Header, name string) string {
+	values := splitList(header.Values(name))
+	if len(values) < 1 {
+		return ""
+	}
+	hops := 1
+	forwarded := splitList(header.Values("X-Forwarded-For"))
+	for i := len(forwarded) - 1; i >= 0 && engine.isTrustedNode(forwarded[i]); i-- {
+		hops++
+	}
+	return values[max(len(values)-hops, 0)]
+}
+
+// isTrustedNode 判断转发头中的节点是否为可信代理
+func (engine *Engine) isTrustedNode(node string) bool {
+	addr, err := netip.ParseAddr(stripPort(node))
+	return err == nil && engine.isTrustedProxy(addr)
+}
+
+// parseForwarded 解析 RFC 7239 Forwarded 请求头
+func parseForwarded(values []string) []map[string]string {
+	elements := make([]map[string]string, 0)
+	for _, value := range values {
+		for _, element := range splitQuoted(value, ',') {
+			pairs := make(map[string]string)
+			for _, pair := range splitQuoted(element, ';') {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				val = strings.TrimSpace(val)
+				if len(val) >= 2 && val[0] == '"' && val[len(val)-1] == '"' {
+					val = strings.ReplaceAll(val[1:len(val)-1], `\"`, `"`)
+				}
+				pairs[strings.ToLower(strings.TrimSpace(key))] = val
+			}
+			elements = append(elements, pairs)
+		}
+	}
+	return elements
+}
+
+// splitQuoted 按分隔符拆分，忽略引号内的分隔符
+func splitQuoted(v string, sep byte) []string {
+	parts := make([]string, 0)
+	quoted := false
+	start := 0
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] == '"' && (i == 0 || v[i-1] != '\\'):
+			quoted = !quoted
+		case v[i] == sep && !quoted:
+			parts = append(parts, v[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, v[start:])
+}
+
+// stripPort 去除地址中的端口及 IPv6 方括号
+func stripPort(v string) string {
+	v = strings.TrimSpace(v)
+	if host, _, err := net.SplitHostPort(v); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(v, "["), "]")
+}
+
+// splitList 拆分逗号分隔列表，多个同名请求头依次拼接
+func splitList(values []string) []string {
+	items := make([]string, 0)
+	for _, value := range values {
+		for _, each := range strings.Split(value, ",") {
+			if each = strings.TrimSpace(each); each != "" {
+				items = append(items, each)
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,264 @@
+package cc
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cquestor/cc/logger"
+	"github.com/cquestor/cc/watcher"
+	"gopkg.in/yaml.v2"
+)
+
+// restartKeys 需重启才能生效的配置项，热加载时保留运行中的取值；
+// 其余配置项如 log.level、trusted-proxies、health 及自定义配置节立即生效
+var restartKeys = []string{
+	"main", "port", "production", "listeners", "tls", "http",
+	"read-timeout", "write-timeout", "idle-timeout",
+	"database", "watch", "reload", "metrics", "tracing",
+	"log.format", "log.file", "log.max-size", "log.max-backups", "log.max-age", "log.rotate-interval", "log.compress",
+}
+
+// ConfigChange 配置变更通知
+type ConfigChange struct {
+	Old             *AppConfig // 变更前的配置快照
+	New             *AppConfig // 变更后的配置快照，需重启的配置项保留运行中的取值
+	Keys            []string   // 发生变化的配置项，点分路径，有序
+	RestartRequired []string   // 发生变化但需重启才能生效的配置项
+}
+
+// Changed 配置项或其下级配置项是否发生变化，如 Changed("cors") 或 Changed("cors.origins")
+func (change *ConfigChange) Changed(key string) bool {
+	for _, changed := range change.Keys {
+		if changed == key || strings.HasPrefix(changed, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// OnConfigChange 订阅配置热加载，配置发生变化时按注册顺序同步调用；
+// 绑定的配置节不会被修改，可通过 Get 从 change.New 读取新值
+func (engine *Engine) OnConfigChange(fn func(*ConfigChange)) {
+	engine.reloadMu.Lock()
+	defer engine.reloadMu.Unlock()
+	engine.changeHooks = append(engine.changeHooks, fn)
+}
+
+// Reload 重新加载配置，校验通过后原子替换当前配置快照并通知订阅者；
+// 校验失败时继续使用原配置。reload.signal 开启时收到 SIGHUP 触发，
+// reload.watch 开启时配置文件变化触发。订阅者在释放锁后调用，可在其中再次订阅或重新加载
+func (engine *Engine) Reload() error {
+	change, hooks, err := engine.reload()
+	if err != nil || change == nil {
+		return err
+	}
+	for _, fn := range hooks {
+		notifyChange(fn, change)
+	}
+	return nil
+}
+
+// reload 重新加载并替换配置快照，返回配置变更及此时的订阅者，无变化时变更为 nil
+func (engine *Engine) reload() (*ConfigChange, []func(*ConfigChange), error) {
+	engine.reloadMu.Lock()
+	defer engine.reloadMu.Unlock()
+	old := engine.Config()
+	if old.raw == nil {
+		return nil, nil, fmt.Errorf("reload config: config not loaded")
+	}
+	config := NewAppConfig()
+	config.registered = old.registered
+	config.decoders = old.decoders
+	for _, item := range old.bindings {
+		target := reflect.New(reflect.TypeOf(item.target).Elem()).Interface()
+		config.bindings = append(config.bindings, binding{key: item.key, target: target})
+	}
+	if err := engine.loadConfig(config); err != nil {
+		return nil, nil, fmt.Errorf("reload config: %w", err)
+	}
+	change := &ConfigChange{Old: old, New: config, Keys: diffConfig(old, config)}
+	for _, key := range change.Keys {
+		if requiresRestart(key) {
+			change.RestartRequired = append(change.RestartRequired, key)
+			LogWarnf("Config %s changed, restart required to take effect", key)
+		}
+	}
+	for _, key := range restartKeys {
+		keepField(reflect.ValueOf(old).Elem(), reflect.ValueOf(config).Elem(), key)
+	}
+	if change.Changed("trusted-proxies") {
+		if err := engine.SetTrustedProxies(config.TrustedProxies...); err != nil {
+			return nil, nil, fmt.Errorf("reload config: %w", err)
+		}
+	}
+	if change.Changed("log.level") {
+		level, _ := logger.ParseLevel(config.Log.Level)
+		Log().SetLevel(level)
+	}
+	engine.snapshot.Store(config)
+	LogInfof("Config reloaded, %d keys changed", len(change.Keys))
+	if len(change.Keys) == 0 {
+		return nil, nil, nil
+	}
+	return change, append([]func(*ConfigChange){}, engine.changeHooks...), nil
+}
+
+// notifyChange 通知订阅者，订阅者 panic 时记录日志
+func notifyChange(fn func(*ConfigChange), change *ConfigChange) {
+	defer func() {
+		if r := recover(); r != nil {
+			LogErrf("Config change subscriber panic: %v", r)
+		}
+	}()
+	fn(change)
+}
+
+// watchConfig 依据配置开启 SIGHUP 及配置文件监听，服务关闭后停止；
+// SIGHUP 同时用于重新打开日志文件，开启 reload.signal 后日志切割发送的 SIGHUP 也会触发重新加载，默认关闭
+func (engine *Engine) watchConfig() error {
+	config := engine.Config()
+	if config.Reload.Signal {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		go func() {
+			defer signal.Stop(signals)
+			for {
+				select {
+				case <-signals:
+					engine.reloadLogged()
+				case <-engine.stopped:
+					return
+				}
+			}
+		}()
+	}
+	if !config.Reload.Watch || config.loader == nil || len(config.loader.files) == 0 {
+		return nil
+	}
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range config.loader.files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		files[path] = true
+		dirs[filepath.Dir(path)] = true
+	}
+	watch, err := watcher.NewWatcher(filepath.Dir(sortedKeys(files)[0]))
+	if err != nil {
+		return err
+	}
+	watch.AddEvent(watcher.CREATE, watcher.WRITE, watcher.RENAME, watcher.REMOVE)
+	for _, dir := range sortedKeys(dirs) {
+		if err := watch.AddWatch(dir); err != nil {
+			watch.Close()
+			return err
+		}
+	}
+	reload := watcher.Debounce(engine.reloadLogged, time.Duration(config.Watch.Debounce)*time.Millisecond)
+	go watch.Watch()
+	go func() {
+		defer watch.Close()
+		for {
+			select {
+			case event := <-watch.Events:
+				if path, err := filepath.Abs(event.Name); err == nil && files[path] {
+					reload()
+				}
+			case err := <-watch.Errs:
+				LogWarnf("Config watcher error: %v", err)
+			case <-engine.stopped:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadLogged 重新加载配置，失败时记录日志
+func (engine *Engine) reloadLogged() {
+	if err := engine.Reload(); err != nil {
+		LogErrf("Could not reload config, keep using the previous one: %v", err)
+	}
+}
+
+// requiresRestart 配置项是否需要重启才能生效
+func requiresRestart(key string) bool {
+	for _, restart := range restartKeys {
+		if key == restart || strings.HasPrefix(key, restart+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// diffConfig 比较两份配置，返回发生变化的配置项，包括自定义配置节
+func diffConfig(old, current *AppConfig) []string {
+	before, after := flattenConfig(old), flattenConfig(current)
+	keys := make([]string, 0)
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || previous != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flattenConfig 将生效配置及自定义配置节展开为 点分路径 → 取值
+func flattenConfig(config *AppConfig) map[string]string {
+	content, _ := yaml.Marshal(config)
+	var raw any
+	yaml.Unmarshal(content, &raw)
+	effective, _ := normalizeYAML(raw).(map[string]any)
+	for key, value := range config.raw {
+		if _, ok := effective[key]; !ok {
+			effective[key] = value
+		}
+	}
+	out := make(map[string]string)
+	flatten("", effective, out)
+	return out
+}
+
+// flatten 展开嵌套映射，列表视为整体
+func flatten(prefix string, v any, out map[string]string) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		out[strings.TrimPrefix(prefix, ".")] = fmt.Sprint(v)
+		return
+	}
+	for key, value := range m {
+		flatten(prefix+"."+key, value, out)
+	}
+}
+
+// keepField 将 yaml 路径 key 对应的字段从 old 复制到 current
+func keepField(old, current reflect.Value, key string) {
+	name, rest, nested := strings.Cut(key, ".")
+	for i := 0; i < old.NumField(); i++ {
+		tag, _, _ := strings.Cut(old.Type().Field(i).Tag.Get("yaml"), ",")
+		if tag != name {
+			continue
+		}
+		if nested {
+			keepField(old.Field(i), current.Field(i), rest)
+		} else {
+			current.Field(i).Set(old.Field(i))
+		}
+		return
+	}
+}
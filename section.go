@@ -0,0 +1,199 @@
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// IValidator 配置校验接口，绑定的配置节实现该接口时在启动阶段校验
+type IValidator interface {
+	Validate() error
+}
+
+// binding 配置加载前登记的配置节
+type binding struct {
+	key    string
+	target any
+}
+
+// Bind 将配置节绑定到结构体指针，如 Bind("mail", &MailConfig{})。
+// 配置节与项目配置共用 YAML、环境变量及命令行参数各层，字段默认值取自 default 标签；
+// 配置加载前调用时延迟到启动阶段绑定，错误由 Start、Serve 或 Run 返回
+func (config *AppConfig) Bind(key string, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("config %s: target must be a non-nil pointer", key)
+	}
+	if config.raw == nil {
+		config.bindings = append(config.bindings, binding{key: key, target: target})
+		return nil
+	}
+	_, err := config.decode(key, target)
+	return err
+}
+
+// Get 读取配置项并转换为指定类型，如 Get[bool](config, "features.beta")；
+// 非结构体类型的配置项不存在时返回错误
+func Get[T any](config *AppConfig, key string) (T, error) {
+	var v T
+	if config.raw == nil {
+		return v, fmt.Errorf("config %s: config not loaded", key)
+	}
+	found, err := config.decode(key, &v)
+	if err == nil && !found && reflect.TypeOf(v) != nil && reflect.TypeOf(v).Kind() != reflect.Struct {
+		err = fmt.Errorf("config %s: not found", key)
+	}
+	return v, err
+}
+
+// load 保存合并后的配置并绑定已登记的配置节
+func (config *AppConfig) load(raw map[string]any, loader *configLoader) error {
+	config.raw = raw
+	config.loader = loader
+	var errs []error
+	for _, binding := range config.bindings {
+		if _, err := config.decode(binding.key, binding.target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// decode 依次应用默认值、环境变量及命令行参数、配置内容，最后校验；
+// 覆盖作用于配置副本，config.raw 加载后只读，可并发调用
+func (config *AppConfig) decode(key string, target any) (bool, error) {
+	value := reflect.ValueOf(target).Elem()
+	if err := setDefaults(value); err != nil {
+		return false, fmt.Errorf("config %s: %w", key, err)
+	}
+	raw := config.raw
+	if config.loader != nil {
+		raw = cloneValue(config.raw).(map[string]any)
+		paths := make(map[string]reflect.Type)
+		if value.Kind() == reflect.Struct {
+			configPaths(value.Type(), key+".", paths)
+		} else {
+			paths[key] = value.Type()
+		}
+		if err := config.loader.overlay(raw, paths); err != nil {
+			return false, fmt.Errorf("config %s: %w", key, err)
+		}
+	}
+	section, found := lookupPath(raw, key)
+	if found {
+		content, err := yaml.Marshal(section)
+		if err != nil {
+			return found, fmt.Errorf("config %s: %w", key, err)
+		}
+		if err := yaml.Unmarshal(content, target); err != nil {
+			return found, fmt.Errorf("config %s: %w", key, err)
+		}
+	}
+	if validator, ok := target.(IValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return found, fmt.Errorf("config %s: %w", key, err)
+		}
+	}
+	return found, nil
+}
+
+// lookupPath 按点分路径查找配置
+func lookupPath(m map[string]any, key string) (any, bool) {
+	var current any = m
+	for _, part := range strings.Split(key, ".") {
+		node, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = node[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setDefaults 依据 default 标签为零值字段设置默认值
+func setDefaults(value reflect.Value) error {
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field, structField := value.Field(i), value.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		if field.Kind() == reflect.Struct {
+			if err := setDefaults(field); err != nil {
+				return err
+			}
+			continue
+		}
+		tag, ok := structField.Tag.Lookup("default")
+		if !ok || !field.IsZero() {
+			continue
+		}
+		if err := setDefault(field, tag); err != nil {
+			return fmt.Errorf("default of %s: %w", structField.Name, err)
+		}
+	}
+	return nil
+}
+
+// setDefault 解析默认值
+func setDefault(field reflect.Value, tag string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(tag)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(tag)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(tag)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(tag, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(tag, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(tag, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		items := strings.Split(tag, ",")
+		slice := reflect.MakeSlice(field.Type(), 0, len(items))
+		for _, item := range items {
+			slice = reflect.Append(slice, reflect.ValueOf(strings.TrimSpace(item)).Convert(field.Type().Elem()))
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"slices"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTPConfig HTTP 协议配置
+type HTTPConfig struct {
+	ReadHeaderTimeout int         `yaml:"read-header-timeout"`
+	MaxHeaderBytes    int         `yaml:"max-header-bytes"`
+	H2C               bool        `yaml:"h2c"`
+	HTTP2             HTTP2Config `yaml:"http2"`
+}
+
+// HTTP2Config HTTP/2 调优参数，为 0 时使用默认值
+type HTTP2Config struct {
+	MaxConcurrentStreams         uint32 `yaml:"max-concurrent-streams"`
+	MaxReadFrameSize             uint32 `yaml:"max-read-frame-size"`
+	MaxDecoderHeaderTableSize    uint32 `yaml:"max-decoder-header-table-size"`
+	MaxEncoderHeaderTableSize    uint32 `yaml:"max-encoder-header-table-size"`
+	MaxUploadBufferPerConnection int32  `yaml:"max-upload-buffer-per-connection"`
+	MaxUploadBufferPerStream     int32  `yaml:"max-upload-buffer-per-stream"`
+}
+
+// newServer 依据配置构造 HTTP 服务，TLS 连接通过 ALPN 协商 HTTP/2，开启 h2c 时明文连接同样支持 HTTP/2
+func (engine *Engine) newServer() (*http.Server, error) {
+	server := engine.baseServer(engine)
+	server.IdleTimeout = time.Duration(engine.config.IdleTimeout) * time.Second
+	server.TLSConfig = engine.tlsConfig
+	if engine.tlsConfig != nil && !slices.Contains(engine.tlsConfig.NextProtos, "h2") {
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		return server, nil
+	}
+	config := engine.config.HTTP.HTTP2
+	h2 := &http2.Server{
+		MaxConcurrentStreams:         config.MaxConcurrentStreams,
+		MaxReadFrameSize:             config.MaxReadFrameSize,
+		MaxDecoderHeaderTableSize:    config.MaxDecoderHeaderTableSize,
+		MaxEncoderHeaderTableSize:    config.MaxEncoderHeaderTableSize,
+		MaxUploadBufferPerConnection: config.MaxUploadBufferPerConnection,
+		MaxUploadBufferPerStream:     config.MaxUploadBufferPerStream,
+		IdleTimeout:                  server.IdleTimeout,
+	}
+	if engine.tlsConfig != nil {
+		if err := http2.ConfigureServer(server, h2); err != nil {
+			return nil, err
+		}
+	}
+	if engine.config.HTTP.H2C {
+		server.Handler = h2c.NewHandler(engine, h2)
+	}
+	return server, nil
+}
+
+// baseServer 构造带超时及请求头限制的 HTTP 服务
+func (engine *Engine) baseServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadTimeout:       time.Duration(engine.config.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(engine.config.HTTP.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(engine.config.WriteTimeout) * time.Second,
+		MaxHeaderBytes:    engine.config.HTTP.MaxHeaderBytes,
+	}
+}
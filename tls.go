@@ -0,0 +1,234 @@
+package cc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cquestor/cc/watcher"
+)
+
+// TLSConfig TLS 配置
+type TLSConfig struct {
+	Cert         string   `yaml:"cert"`
+	Key          string   `yaml:"key"`
+	MinVersion   string   `yaml:"min-version"`
+	CipherSuites []string `yaml:"cipher-suites"`
+	ALPN         []string `yaml:"alpn"`
+	ClientCA     string   `yaml:"client-ca"`
+	ClientAuth   string   `yaml:"client-auth"`
+	Reload       bool     `yaml:"reload"`
+}
+
+// certReloader 证书热加载，证书文件变化后重新加载，加载失败时继续使用旧证书
+type certReloader struct {
+	certPath string
+	keyPath  string
+	cert     atomic.Pointer[tls.Certificate]
+	watch    *watcher.Watcher
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// tlsVersions TLS 版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuthTypes 客户端证书校验方式
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// hasCert 是否配置了 TLS 证书
+func (engine *Engine) hasCert() bool {
+	return engine.config.TLS.Cert != "" && engine.config.TLS.Key != ""
+}
+
+// initTLS 依据配置构造 TLS 配置，运行参数中的证书优先于配置文件
+func (engine *Engine) initTLS() error {
+	config := &engine.config.TLS
+	if certPath, ok := engine.options[optCertPath].(CTLSCertFile); ok {
+		config.Cert = string(certPath)
+	}
+	if keyPath, ok := engine.options[optKeyPath].(CTLSKeyFile); ok {
+		config.Key = string(keyPath)
+	}
+	if !engine.hasCert() {
+		return nil
+	}
+	tlsConfig, err := engine.buildTLSConfig(config)
+	if err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	engine.tlsConfig = tlsConfig
+	return nil
+}
+
+// buildTLSConfig 构造 *tls.Config
+func (engine *Engine) buildTLSConfig(config *TLSConfig) (*tls.Config, error) {
+	reloader, err := newCertReloader(config.Cert, config.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     config.ALPN,
+	}
+	version, ok := tlsVersions[config.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown min version: %s", config.MinVersion)
+	}
+	tlsConfig.MinVersion = version
+	if len(config.CipherSuites) > 0 {
+		suites := make(map[string]uint16)
+		for _, suite := range tls.CipherSuites() {
+			suites[suite.Name] = suite.ID
+		}
+		for _, name := range config.CipherSuites {
+			id, ok := suites[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown or insecure cipher suite: %s", name)
+			}
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+		}
+	}
+	if config.ClientCA != "" {
+		content, err := os.ReadFile(config.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no certificates found in %s", config.ClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if config.ClientAuth != "" {
+		clientAuth, ok := clientAuthTypes[config.ClientAuth]
+		if !ok {
+			return nil, fmt.Errorf("unknown client auth: %s", config.ClientAuth)
+		}
+		if clientAuth >= tls.VerifyClientCertIfGiven && tlsConfig.ClientCAs == nil {
+			return nil, fmt.Errorf("client auth %s requires client-ca", config.ClientAuth)
+		}
+		tlsConfig.ClientAuth = clientAuth
+	}
+	if config.Reload {
+		if err := reloader.Watch(time.Duration(engine.config.Watch.Debounce) * time.Millisecond); err != nil {
+			return nil, err
+		}
+		engine.certReloader = reloader
+	}
+	return tlsConfig, nil
+}
+
+// newCertReloader 构造证书热加载
+func newCertReloader(certPath, keyPath string) (*certReloader, error) {
+	reloader := &certReloader{certPath: certPath, keyPath: keyPath, done: make(chan struct{})}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate 用于 tls.Config.GetCertificate
+func (reloader *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return reloader.cert.Load(), nil
+}
+
+// Watch 监听证书所在目录，变化后防抖重新加载
+func (reloader *certReloader) Watch(debounce time.Duration) error {
+	certDir, err := filepath.Abs(filepath.Dir(reloader.certPath))
+	if err != nil {
+		return err
+	}
+	keyDir, err := filepath.Abs(filepath.Dir(reloader.keyPath))
+	if err != nil {
+		return err
+	}
+	watch, err := watcher.NewWatcher(certDir)
+	if err != nil {
+		return err
+	}
+	watch.AddEvent(watcher.CREATE, watcher.WRITE, watcher.RENAME, watcher.REMOVE)
+	for _, dir := range []string{certDir, keyDir} {
+		rel, _ := filepath.Rel(certDir, dir)
+		watch.AddIncludes(rel)
+		if err := watch.AddWatch(dir); err != nil {
+			watch.Close()
+			return err
+		}
+	}
+	reloader.watch = watch
+	reload := watcher.Debounce(func() {
+		select {
+		case <-reloader.done:
+			return
+		default:
+		}
+		if err := reloader.load(); err != nil {
+			LogErrf("Could not reload certificate, keep using the previous one: %v", err)
+			return
+		}
+		LogInfof("Certificate reloaded from %s", reloader.certPath)
+	}, debounce)
+	// 事件需持续读取至 watch.Watch 返回，否则其阻塞在发送上无法退出
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		watch.Watch()
+	}()
+	reloader.stopped = make(chan struct{})
+	go func() {
+		defer close(reloader.stopped)
+		for {
+			select {
+			case <-watch.Events:
+				reload()
+			case err := <-watch.Errs:
+				LogWarnf("Certificate watcher error: %v", err)
+			case <-exited:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Close 停止监听，等待监听协程退出
+func (reloader *certReloader) Close() {
+	reloader.once.Do(func() {
+		close(reloader.done)
+		if reloader.watch != nil {
+			reloader.watch.Close()
+		}
+		if reloader.stopped != nil {
+			<-reloader.stopped
+		}
+	})
+}
+
+// load 加载证书
+func (reloader *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(reloader.certPath, reloader.keyPath)
+	if err != nil {
+		return err
+	}
+	reloader.cert.Store(&cert)
+	return nil
+}
@@ -0,0 +1,192 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// scopeName 仪表库名称
+const scopeName = "github.com/cquestor/cc"
+
+// IExporter 跨度导出器
+type IExporter interface {
+	Export(ctx context.Context, spans []*SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// StdoutExporter 以 JSON 行的形式输出跨度，便于本地调试
+type StdoutExporter struct {
+	mu     sync.Mutex
+	Writer io.Writer
+}
+
+// OTLPExporter 以 OTLP/HTTP JSON 协议导出跨度
+type OTLPExporter struct {
+	Endpoint    string
+	ServiceName string
+	Headers     map[string]string
+	Client      *http.Client
+}
+
+// NewStdoutExporter 构造标准输出导出器，w 为 nil 时输出到 os.Stdout
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &StdoutExporter{Writer: w}
+}
+
+// Export 实现 IExporter 接口
+func (exporter *StdoutExporter) Export(ctx context.Context, spans []*SpanData) error {
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	encoder := json.NewEncoder(exporter.Writer)
+	for _, span := range spans {
+		attributes := make(map[string]any, len(span.Attributes))
+		for _, attribute := range span.Attributes {
+			attributes[attribute.Key] = attribute.Value
+		}
+		record := map[string]any{
+			"name":       span.Name,
+			"kind":       span.Kind,
+			"trace_id":   span.SpanContext.TraceID.String(),
+			"span_id":    span.SpanContext.SpanID.String(),
+			"start":      span.StartTime.Format(time.RFC3339Nano),
+			"duration":   span.EndTime.Sub(span.StartTime).String(),
+			"attributes": attributes,
+			"status":     span.StatusCode,
+		}
+		if span.Parent.IsValid() {
+			record["parent_id"] = span.Parent.String()
+		}
+		if span.StatusMessage != "" {
+			record["status_message"] = span.StatusMessage
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown 实现 IExporter 接口
+func (exporter *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// NewOTLPExporter 构造 OTLP/HTTP 导出器，endpoint 如 http://localhost:4318/v1/traces
+func NewOTLPExporter(endpoint, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		Endpoint:    endpoint,
+		ServiceName: serviceName,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Export 实现 IExporter 接口
+func (exporter *OTLPExporter) Export(ctx context.Context, spans []*SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(exporter.encode(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, exporter.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range exporter.Headers {
+		req.Header.Set(key, value)
+	}
+	client := exporter.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp export failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// Shutdown 实现 IExporter 接口
+func (exporter *OTLPExporter) Shutdown(ctx context.Context) error {
+	if exporter.Client != nil {
+		exporter.Client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// encode 编码为 OTLP JSON 请求体
+func (exporter *OTLPExporter) encode(spans []*SpanData) map[string]any {
+	encoded := make([]map[string]any, 0, len(spans))
+	for _, span := range spans {
+		item := map[string]any{
+			"traceId":           span.SpanContext.TraceID.String(),
+			"spanId":            span.SpanContext.SpanID.String(),
+			"name":              span.Name,
+			"kind":              span.Kind,
+			"startTimeUnixNano": strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.Attributes),
+			"status":            map[string]any{"code": span.StatusCode, "message": span.StatusMessage},
+		}
+		if span.Parent.IsValid() {
+			item["parentSpanId"] = span.Parent.String()
+		}
+		if span.SpanContext.TraceState != "" {
+			item["traceState"] = span.SpanContext.TraceState
+		}
+		encoded = append(encoded, item)
+	}
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": otlpAttributes([]Attribute{{Key: "service.name", Value: exporter.ServiceName}}),
+			},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": scopeName},
+				"spans": encoded,
+			}},
+		}},
+	}
+}
+
+// otlpAttributes 编码为 OTLP 属性列表
+func otlpAttributes(attributes []Attribute) []any {
+	encoded := make([]any, 0, len(attributes))
+	for _, attribute := range attributes {
+		var value map[string]any
+		switch v := attribute.Value.(type) {
+		case string:
+			value = map[string]any{"stringValue": v}
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int:
+			value = map[string]any{"intValue": strconv.FormatInt(int64(v), 10)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": v}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, map[string]any{"key": attribute.Key, "value": value})
+	}
+	return encoded
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+)
+
+// QueryHook 为数据库查询创建子跨度的 orm 钩子
+type QueryHook struct {
+	tracer *Tracer
+	system string
+}
+
+// querySpanKey 查询跨度上下文键
+type querySpanKey struct{}
+
+// NewQueryHook 构造查询钩子，system 为数据库类型，如 mysql
+func NewQueryHook(tracer *Tracer, system string) *QueryHook {
+	return &QueryHook{tracer: tracer, system: system}
+}
+
+// BeforeQuery 实现 orm.IHook 接口，仅在已有父跨度时创建子跨度
+func (hook *QueryHook) BeforeQuery(ctx context.Context, query string, args []any) context.Context {
+	if !SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	ctx, span := hook.tracer.Start(ctx, strings.ToUpper(operation), KindClient,
+		"db.system", hook.system,
+		"db.operation", strings.ToUpper(operation),
+		"db.statement", query,
+	)
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+// AfterQuery 实现 orm.IHook 接口
+func (hook *QueryHook) AfterQuery(ctx context.Context, query string, err error) {
+	span, ok := ctx.Value(querySpanKey{}).(*Span)
+	if !ok || span.tracer != hook.tracer {
+		return
+	}
+	span.RecordError(err)
+	span.End()
+}
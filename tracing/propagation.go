@@ -0,0 +1,192 @@
+// Package tracing 提供兼容 W3C Trace Context 的分布式链路追踪
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceID 链路 ID
+type TraceID [16]byte
+
+// SpanID 跨度 ID
+type SpanID [8]byte
+
+// FlagSampled 采样标记
+const FlagSampled byte = 0x01
+
+// SpanContext 跨度上下文，在进程间传递
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	Remote     bool
+}
+
+// remoteKey 远程跨度上下文键
+type remoteKey struct{}
+
+// IsValid 是否为有效链路 ID
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String 十六进制表示
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid 是否为有效跨度 ID
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String 十六进制表示
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid 链路 ID 及跨度 ID 均有效
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Sampled 是否被采样
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent 生成 traceparent 请求头
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent 解析 traceparent 请求头
+func ParseTraceparent(v string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent: %q", v)
+	}
+	version, err := decodeHex(parts[0], 1)
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, fmt.Errorf("invalid traceparent version: %q", v)
+	}
+	traceID, err := decodeHex(parts[1], 16)
+	if err != nil {
+		return sc, fmt.Errorf("invalid trace id: %q", v)
+	}
+	spanID, err := decodeHex(parts[2], 8)
+	if err != nil {
+		return sc, fmt.Errorf("invalid span id: %q", v)
+	}
+	flags, err := decodeHex(parts[3], 1)
+	if err != nil {
+		return sc, fmt.Errorf("invalid trace flags: %q", v)
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent: %q", v)
+	}
+	sc.Remote = true
+	return sc, nil
+}
+
+// Extract 从请求头中解析跨度上下文
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(header.Get("traceparent"))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = parseTraceState(header.Values("tracestate"))
+	return sc, true
+}
+
+// Inject 将上下文中的跨度写入请求头，用于调用下游服务
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	header.Set("traceparent", sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set("tracestate", sc.TraceState)
+	} else {
+		header.Del("tracestate")
+	}
+}
+
+// ContextWithRemote 构造携带远程跨度上下文的上下文，作为新跨度的父级
+func ContextWithRemote(parent context.Context, sc SpanContext) context.Context {
+	return context.WithValue(parent, remoteKey{}, sc)
+}
+
+// SpanContextFromContext 获取上下文中当前跨度的跨度上下文
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	if sc, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		return sc
+	}
+	return SpanContext{}
+}
+
+// parseTraceState 合并并校验 tracestate，最多保留 32 项
+func parseTraceState(values []string) string {
+	members := make([]string, 0)
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			member = strings.TrimSpace(member)
+			if member == "" {
+				continue
+			}
+			key, val, ok := strings.Cut(member, "=")
+			if !ok || key == "" || val == "" || len(key) > 256 || len(val) > 256 {
+				return ""
+			}
+			members = append(members, member)
+		}
+	}
+	if len(members) > 32 {
+		return ""
+	}
+	return strings.Join(members, ",")
+}
+
+// decodeHex 解码小写十六进制
+func decodeHex(v string, n int) ([]byte, error) {
+	if strings.ToLower(v) != v {
+		return nil, fmt.Errorf("hex must be lowercase")
+	}
+	b, err := hex.DecodeString(v)
+	if err != nil || len(b) != n {
+		return nil, fmt.Errorf("invalid hex: %s", v)
+	}
+	return b, nil
+}
+
+// newTraceID 生成链路 ID
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID 生成跨度 ID
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
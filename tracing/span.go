@@ -0,0 +1,171 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SpanKind 跨度类型
+type SpanKind int
+
+// 跨度类型，取值与 OTLP 一致
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// StatusCode 跨度状态码，取值与 OTLP 一致
+type StatusCode int
+
+// 跨度状态码
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Attribute 跨度属性
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// SpanData 结束后的跨度快照，交由导出器导出
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    []Attribute
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+// Span 跨度
+type Span struct {
+	mu     sync.Mutex
+	tracer *Tracer
+	data   SpanData
+	ended  bool
+}
+
+// badKey 无法解析的属性键
+const badKey = "!BADKEY"
+
+// spanKey 跨度上下文键
+type spanKey struct{}
+
+// ContextWithSpan 构造携带跨度的上下文
+func ContextWithSpan(parent context.Context, span *Span) context.Context {
+	return context.WithValue(parent, spanKey{}, span)
+}
+
+// SpanFromContext 获取上下文中的跨度，不存在时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContext 获取跨度上下文
+func (span *Span) SpanContext() SpanContext {
+	return span.data.SpanContext
+}
+
+// IsRecording 跨度是否被采样且尚未结束
+func (span *Span) IsRecording() bool {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	return !span.ended && span.data.SpanContext.Sampled()
+}
+
+// SetName 设置跨度名称
+func (span *Span) SetName(name string) {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	if !span.ended {
+		span.data.Name = name
+	}
+}
+
+// SetAttributes 设置跨度属性，参数为键值对
+func (span *Span) SetAttributes(kv ...any) {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	if span.ended {
+		return
+	}
+	for len(kv) > 0 {
+		switch key := kv[0].(type) {
+		case Attribute:
+			span.setAttribute(key.Key, key.Value)
+			kv = kv[1:]
+		case string:
+			if len(kv) < 2 {
+				span.setAttribute(badKey, key)
+				kv = kv[1:]
+			} else {
+				span.setAttribute(key, kv[1])
+				kv = kv[2:]
+			}
+		default:
+			span.setAttribute(badKey, key)
+			kv = kv[1:]
+		}
+	}
+}
+
+// SetStatus 设置跨度状态
+func (span *Span) SetStatus(code StatusCode, message string) {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	if span.ended {
+		return
+	}
+	span.data.StatusCode = code
+	if code == StatusError {
+		span.data.StatusMessage = message
+	} else {
+		span.data.StatusMessage = ""
+	}
+}
+
+// RecordError 记录错误并将跨度标记为失败
+func (span *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	span.SetAttributes("error.type", fmt.Sprintf("%T", err))
+	span.SetStatus(StatusError, err.Error())
+}
+
+// End 结束跨度，重复调用无效
+func (span *Span) End() {
+	span.mu.Lock()
+	if span.ended {
+		span.mu.Unlock()
+		return
+	}
+	span.ended = true
+	span.data.EndTime = time.Now()
+	data := span.data
+	span.mu.Unlock()
+	if data.SpanContext.Sampled() {
+		span.tracer.export(&data)
+	}
+}
+
+// setAttribute 设置属性，已存在时覆盖
+func (span *Span) setAttribute(key string, value any) {
+	for i := range span.data.Attributes {
+		if span.data.Attributes[i].Key == key {
+			span.data.Attributes[i].Value = value
+			return
+		}
+	}
+	span.data.Attributes = append(span.data.Attributes, Attribute{Key: key, Value: value})
+}
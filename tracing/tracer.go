@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 批量导出参数
+const (
+	defaultQueueSize    = 2048
+	defaultBatchSize    = 512
+	defaultBatchTimeout = 5 * time.Second
+	errorInterval       = time.Minute // 导出错误的最短报告间隔
+)
+
+// Tracer 链路追踪器，按批次异步导出结束的跨度
+type Tracer struct {
+	exporter    IExporter
+	sampleRatio float64
+	queue       chan *SpanData
+	flushc      chan chan struct{}
+	done        chan struct{}
+	closeOnce   sync.Once
+	wg          sync.WaitGroup
+	dropped     atomic.Uint64
+	onError     atomic.Pointer[func(error)]
+	lastReport  time.Time
+	failed      int
+}
+
+// NewTracer 构造链路追踪器，sampleRatio 为根跨度采样率，取值 [0, 1]
+func NewTracer(exporter IExporter, sampleRatio float64) *Tracer {
+	tracer := &Tracer{
+		exporter:    exporter,
+		sampleRatio: sampleRatio,
+		queue:       make(chan *SpanData, defaultQueueSize),
+		flushc:      make(chan chan struct{}),
+		done:        make(chan struct{}),
+	}
+	tracer.wg.Add(1)
+	go tracer.loop()
+	return tracer
+}
+
+// SetErrorHandler 设置导出错误处理函数，同类错误每分钟至多报告一次，默认输出到标准错误
+func (tracer *Tracer) SetErrorHandler(fn func(err error)) {
+	tracer.onError.Store(&fn)
+}
+
+// Dropped 因导出失败或队列已满而丢弃的跨度总数
+func (tracer *Tracer) Dropped() uint64 {
+	return tracer.dropped.Load()
+}
+
+// Start 开始一个跨度，父级取自上下文中的本地或远程跨度
+func (tracer *Tracer) Start(ctx context.Context, name string, kind SpanKind, kv ...any) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+		if tracer.shouldSample(sc.TraceID) {
+			sc.Flags |= FlagSampled
+		}
+	}
+	span := &Span{tracer: tracer, data: SpanData{
+		Name:        name,
+		Kind:        kind,
+		SpanContext: sc,
+		Parent:      parent.SpanID,
+		StartTime:   time.Now(),
+	}}
+	span.SetAttributes(kv...)
+	return ContextWithSpan(ctx, span), span
+}
+
+// ForceFlush 立即导出队列中的跨度
+func (tracer *Tracer) ForceFlush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case tracer.flushc <- done:
+	case <-tracer.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown 导出剩余跨度并关闭导出器
+func (tracer *Tracer) Shutdown(ctx context.Context) error {
+	tracer.closeOnce.Do(func() { close(tracer.done) })
+	finished := make(chan struct{})
+	go func() {
+		tracer.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return tracer.exporter.Shutdown(ctx)
+}
+
+// export 将结束的跨度加入导出队列，队列已满时丢弃并计数
+func (tracer *Tracer) export(data *SpanData) {
+	select {
+	case <-tracer.done:
+		return
+	default:
+	}
+	select {
+	case tracer.queue <- data:
+	default:
+		tracer.dropped.Add(1)
+	}
+}
+
+// reportError 记录导出失败的跨度，距上次报告超过 errorInterval 时报告错误及期间失败的跨度数，仅在导出循环中调用
+func (tracer *Tracer) reportError(err error, spans int) {
+	tracer.dropped.Add(uint64(spans))
+	tracer.failed += spans
+	if !tracer.lastReport.IsZero() && time.Since(tracer.lastReport) < errorInterval {
+		return
+	}
+	err = fmt.Errorf("export %d spans failed: %w", tracer.failed, err)
+	tracer.lastReport = time.Now()
+	tracer.failed = 0
+	if fn := tracer.onError.Load(); fn != nil {
+		(*fn)(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "tracing: %v\n", err)
+}
+
+// loop 批量导出循环
+func (tracer *Tracer) loop() {
+	defer tracer.wg.Done()
+	ticker := time.NewTicker(defaultBatchTimeout)
+	defer ticker.Stop()
+	batch := make([]*SpanData, 0, defaultBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultBatchTimeout)
+		if err := tracer.exporter.Export(ctx, batch); err != nil {
+			tracer.reportError(err, len(batch))
+		}
+		cancel()
+		batch = make([]*SpanData, 0, defaultBatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-tracer.queue:
+				batch = append(batch, data)
+				if len(batch) >= defaultBatchSize {
+					flush()
+				}
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case data := <-tracer.queue:
+			batch = append(batch, data)
+			if len(batch) >= defaultBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case done := <-tracer.flushc:
+			drain()
+			flush()
+			close(done)
+		case <-tracer.done:
+			drain()
+			flush()
+			return
+		}
+	}
+}
+
+// shouldSample 依据链路 ID 决定根跨度是否采样，同一链路结果一致
+func (tracer *Tracer) shouldSample(id TraceID) bool {
+	if tracer.sampleRatio >= 1 {
+		return true
+	}
+	if tracer.sampleRatio <= 0 {
+		return false
+	}
+	bound := uint64(tracer.sampleRatio * (1 << 63))
+	return binary.BigEndian.Uint64(id[8:])>>1 < bound
+}
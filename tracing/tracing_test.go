@@ -0,0 +1,172 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cquestor/cc/tracing"
+)
+
+func TestTraceparent(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		v := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		sc, err := tracing.ParseTraceparent(v)
+		if err != nil {
+			t.Fatalf("parse traceparent error: %v\n", err)
+		}
+		if !sc.Sampled() || !sc.Remote || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Fatalf("parse traceparent error: %+v\n", sc)
+		}
+		if sc.Traceparent() != v {
+			t.Fatalf("format traceparent error: %s\n", sc.Traceparent())
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, v := range []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		} {
+			if _, err := tracing.ParseTraceparent(v); err == nil {
+				t.Fatalf("traceparent %q should be invalid\n", v)
+			}
+		}
+	})
+	t.Run("propagation", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		header.Add("tracestate", "congo=t61rcWkgMzE")
+		header.Add("tracestate", "rojo=00f067aa0ba902b7")
+		sc, ok := tracing.Extract(header)
+		if !ok || sc.TraceState != "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7" {
+			t.Fatalf("extract error: %+v\n", sc)
+		}
+		tracer := tracing.NewTracer(tracing.NewStdoutExporter(io.Discard), 1)
+		defer tracer.Shutdown(context.Background())
+		ctx, span := tracer.Start(tracing.ContextWithRemote(context.Background(), sc), "client", tracing.KindClient)
+		defer span.End()
+		out := http.Header{}
+		tracing.Inject(ctx, out)
+		child, err := tracing.ParseTraceparent(out.Get("traceparent"))
+		if err != nil || child.TraceID != sc.TraceID || child.SpanID == sc.SpanID || out.Get("tracestate") != sc.TraceState {
+			t.Fatalf("inject error: %v\n", out)
+		}
+	})
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("otlp", func(t *testing.T) {
+		var mu sync.Mutex
+		var requests []map[string]any
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			requests = append(requests, body)
+			mu.Unlock()
+		}))
+		defer collector.Close()
+		tracer := tracing.NewTracer(tracing.NewOTLPExporter(collector.URL+"/v1/traces", "demo"), 1)
+		ctx, parent := tracer.Start(context.Background(), "GET", tracing.KindServer)
+		hook := tracing.NewQueryHook(tracer, "mysql")
+		queryCtx := hook.BeforeQuery(ctx, "SELECT * FROM account", nil)
+		hook.AfterQuery(queryCtx, "SELECT * FROM account", errors.New("boom"))
+		parent.SetName("GET /user/:name")
+		parent.SetAttributes("http.response.status_code", 200)
+		parent.End()
+		if err := tracer.Shutdown(context.Background()); err != nil {
+			t.Fatalf("shutdown error: %v\n", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(requests) != 1 {
+			t.Fatalf("collector received %d requests\n", len(requests))
+		}
+		resource := requests[0]["resourceSpans"].([]any)[0].(map[string]any)
+		service := resource["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+		if service["value"].(map[string]any)["stringValue"] != "demo" {
+			t.Fatalf("service name error: %v\n", service)
+		}
+		spans := resource["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+		if len(spans) != 2 {
+			t.Fatalf("span count error: %d\n", len(spans))
+		}
+		query, server := spans[0].(map[string]any), spans[1].(map[string]any)
+		if query["name"] != "SELECT" || query["parentSpanId"] != server["spanId"] || query["traceId"] != server["traceId"] {
+			t.Fatalf("query span error: %v\n", query)
+		}
+		if query["status"].(map[string]any)["code"] != float64(tracing.StatusError) {
+			t.Fatalf("query status error: %v\n", query["status"])
+		}
+		if server["name"] != "GET /user/:name" || server["kind"] != float64(tracing.KindServer) {
+			t.Fatalf("server span error: %v\n", server)
+		}
+	})
+	t.Run("sampling", func(t *testing.T) {
+		var out bytes.Buffer
+		tracer := tracing.NewTracer(tracing.NewStdoutExporter(&out), 0)
+		ctx, span := tracer.Start(context.Background(), "root", tracing.KindServer)
+		_, child := tracer.Start(ctx, "child", tracing.KindInternal)
+		child.End()
+		span.End()
+		tracer.Shutdown(context.Background())
+		if span.SpanContext().Sampled() || out.Len() != 0 {
+			t.Fatalf("unsampled span exported: %s\n", out.String())
+		}
+	})
+	t.Run("stdout", func(t *testing.T) {
+		var out bytes.Buffer
+		tracer := tracing.NewTracer(tracing.NewStdoutExporter(&out), 1)
+		_, span := tracer.Start(context.Background(), "job", tracing.KindInternal, "attempt", 2)
+		span.End()
+		span.End()
+		if err := tracer.ForceFlush(context.Background()); err != nil {
+			t.Fatalf("flush error: %v\n", err)
+		}
+		if strings.Count(out.String(), "\n") != 1 || !strings.Contains(out.String(), `"attempt":2`) {
+			t.Fatalf("stdout exporter error: %s\n", out.String())
+		}
+		tracer.Shutdown(context.Background())
+	})
+	t.Run("export error", func(t *testing.T) {
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer collector.Close()
+		tracer := tracing.NewTracer(tracing.NewOTLPExporter(collector.URL, "demo"), 1)
+		var reported []error
+		tracer.SetErrorHandler(func(err error) {
+			reported = append(reported, err)
+		})
+		for i := 0; i < 2; i++ {
+			_, span := tracer.Start(context.Background(), "job", tracing.KindInternal)
+			span.End()
+			if err := tracer.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("flush error: %v\n", err)
+			}
+		}
+		tracer.Shutdown(context.Background())
+		if tracer.Dropped() != 2 || len(reported) != 1 || !strings.Contains(reported[0].Error(), "export 1 spans failed") {
+			t.Fatalf("export errors should be counted and rate limited: %d %v\n", tracer.Dropped(), reported)
+		}
+	})
+}
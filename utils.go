@@ -26,7 +26,7 @@ func handleMiddlewares(ctx *Context, middlewares []IHandler) Response {
 func handleErr(ctx *Context) {
 	if err := recover(); err != nil {
 		message := trace(fmt.Sprintf("%s", err))
-		LogErrf("%s\n\n", message)
+		ctx.Logger().Error(message)
 		Code(http.StatusInternalServerError).Invoke(ctx)
 	}
 }
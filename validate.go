@@ -0,0 +1,262 @@
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cquestor/cc/logger"
+	"github.com/cquestor/cc/orm"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigError 配置错误汇总，启动前一次性报告全部问题
+type ConfigError struct {
+	Problems []string
+}
+
+// yamlLine yaml.v2 错误信息中的行号
+var yamlLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// yamlUnknown yaml.v2 严格模式下的未知字段
+var yamlUnknown = regexp.MustCompile(`^field (\S+) (not found|already set) in type .*$`)
+
+// mysqlDSN MySQL 连接串，[user[:password]@][net[(addr)]]/dbname[?param=value]
+var mysqlDSN = regexp.MustCompile(`^(?:[^@/]*@)?(?:\w+(?:\([^)]*\))?)?/[^?]*(?:\?(.*))?$`)
+
+// Error 实现 error 接口
+func (err *ConfigError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(err.Problems, "\n  ")
+}
+
+// add 添加问题，展开 errors.Join 合并的错误
+func (err *ConfigError) add(e error) {
+	if e == nil {
+		return
+	}
+	if joined, ok := e.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			err.add(e)
+		}
+		return
+	}
+	if typeErr, ok := e.(*yaml.TypeError); ok {
+		for _, message := range typeErr.Errors {
+			err.Problems = append(err.Problems, message)
+		}
+		return
+	}
+	err.Problems = append(err.Problems, e.Error())
+}
+
+// err 无问题时返回 nil
+func (err *ConfigError) err() error {
+	if len(err.Problems) == 0 {
+		return nil
+	}
+	return err
+}
+
+// Register 登记自由格式的顶层配置节，严格校验时允许这些键，可通过 Get 读取
+func (config *AppConfig) Register(keys ...string) {
+	config.registered = append(config.registered, keys...)
+}
+
+// strictType 构造严格校验使用的类型：项目配置字段加上已登记及已绑定的顶层配置节
+func (config *AppConfig) strictType() reflect.Type {
+	t := reflect.TypeOf(*config)
+	fields := make([]reflect.StructField, 0, t.NumField())
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() {
+			fields = append(fields, field)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			known[name] = true
+		}
+	}
+	addField := func(key string, fieldType reflect.Type) {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Section%d", len(fields)),
+			Type: fieldType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`yaml:%q`, key)),
+		})
+		known[key] = true
+	}
+	for _, binding := range config.bindings {
+		if !strings.Contains(binding.key, ".") && !known[binding.key] {
+			addField(binding.key, reflect.TypeOf(binding.target).Elem())
+		}
+	}
+	anyType := reflect.TypeOf((*any)(nil)).Elem()
+	for _, binding := range config.bindings {
+		if key, _, _ := strings.Cut(binding.key, "."); !known[key] {
+			addField(key, anyType)
+		}
+	}
+	for _, key := range config.registered {
+		if key, _, _ = strings.Cut(key, "."); !known[key] {
+			addField(key, anyType)
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// checkStrict 严格解码单层配置，返回未知键及类型错误，positions 为 true 时带 文件:行号；
+// 含占位符的行展开后才能确定类型，不做类型检查
+func checkStrict(content []byte, source string, t reflect.Type, positions bool) []string {
+	target := reflect.New(t).Interface()
+	err := yaml.UnmarshalStrict(content, target)
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		return nil
+	}
+	lines := strings.Split(string(content), "\n")
+	problems := make([]string, 0, len(typeErr.Errors))
+	for _, message := range typeErr.Errors {
+		match := yamlLine.FindStringSubmatch(message)
+		if match == nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", source, message))
+			continue
+		}
+		line, detail := match[1], match[2]
+		if unknown := yamlUnknown.FindStringSubmatch(detail); unknown != nil {
+			if unknown[2] == "not found" {
+				detail = fmt.Sprintf("unknown key %q", unknown[1])
+			} else {
+				detail = fmt.Sprintf("duplicate key %q", unknown[1])
+			}
+		} else if n, _ := strconv.Atoi(line); n > 0 && n <= len(lines) && strings.Contains(lines[n-1], "${") {
+			continue
+		} else if i := strings.Index(detail, " into struct {"); i >= 0 {
+			detail = detail[:i] + " into mapping"
+		}
+		position := source
+		if positions {
+			position += ":" + line
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", position, detail))
+	}
+	return problems
+}
+
+// Validate 校验配置取值，返回全部问题
+func (config *AppConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, v ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, v...)))
+		}
+	}
+	if len(config.Listeners) == 0 {
+		// 0 表示绑定随机端口，启动后通过 Addr() 获取
+		check(config.Port >= 0 && config.Port <= 65535, "port", "must be between 0 and 65535, got %d", config.Port)
+	}
+	for i, listener := range config.Listeners {
+		check(listener.Address != "", fmt.Sprintf("listeners[%d].address", i), "must not be empty")
+		if listener.Mode != "" {
+			_, err := strconv.ParseUint(listener.Mode, 8, 32)
+			check(err == nil, fmt.Sprintf("listeners[%d].mode", i), "must be an octal permission such as \"0660\", got %q", listener.Mode)
+		}
+	}
+	for _, item := range []struct {
+		key   string
+		value int
+	}{
+		{"read-timeout", config.ReadTimeout},
+		{"write-timeout", config.WriteTimeout},
+		{"idle-timeout", config.IdleTimeout},
+		{"shutdown-timeout", config.ShutdownTimeout},
+		{"hook-timeout", config.HookTimeout},
+		{"http.read-header-timeout", config.HTTP.ReadHeaderTimeout},
+		{"http.max-header-bytes", config.HTTP.MaxHeaderBytes},
+		{"health.timeout", config.Health.Timeout},
+		{"watch.debounce", int(config.Watch.Debounce)},
+		{"database.connect-backoff", config.Database.ConnectBackoff},
+	} {
+		check(item.value > 0, item.key, "must be positive, got %d", item.value)
+	}
+	for _, item := range []struct {
+		key   string
+		value int
+	}{
+		{"database.max-open-conns", config.Database.MaxOpenConns},
+		{"database.max-idle-conns", config.Database.MaxIdleConns},
+		{"database.conn-max-lifetime", config.Database.ConnMaxLifetime},
+		{"database.conn-max-idle-time", config.Database.ConnMaxIdleTime},
+		{"database.connect-retries", config.Database.ConnectRetries},
+		{"database.stats-interval", config.Database.StatsInterval},
+		{"log.max-size", config.Log.MaxSize},
+		{"log.max-backups", config.Log.MaxBackups},
+		{"log.max-age", config.Log.MaxAge},
+		{"log.rotate-interval", config.Log.RotateInterval},
+		{"health.drain-delay", config.Health.DrainDelay},
+	} {
+		check(item.value >= 0, item.key, "must not be negative, got %d", item.value)
+	}
+	// 排空等待计入 shutdown-timeout，不小于超时时关闭请求、执行钩子时已无剩余时间
+	check(config.Health.DrainDelay < config.ShutdownTimeout, "health.drain-delay", "must be less than shutdown-timeout (%d), got %d", config.ShutdownTimeout, config.Health.DrainDelay)
+	if config.Database.Source != "" {
+		if err := validateDSN(config.Database.Source); err != nil {
+			errs = append(errs, fmt.Errorf("database.source: %w", err))
+		}
+	}
+	_, err := logger.ParseLevel(config.Log.Level)
+	check(err == nil, "log.level", "unknown level %q", config.Log.Level)
+	check(config.Log.Format == "text" || config.Log.Format == "json", "log.format", "must be text or json, got %q", config.Log.Format)
+	check(strings.HasPrefix(config.Metrics.Path, "/"), "metrics.path", "must start with /, got %q", config.Metrics.Path)
+	check(strings.HasPrefix(config.Health.LivenessPath, "/"), "health.liveness-path", "must start with /, got %q", config.Health.LivenessPath)
+	check(strings.HasPrefix(config.Health.ReadinessPath, "/"), "health.readiness-path", "must start with /, got %q", config.Health.ReadinessPath)
+	check(config.Tracing.SampleRatio >= 0 && config.Tracing.SampleRatio <= 1, "tracing.sample-ratio", "must be between 0 and 1, got %v", config.Tracing.SampleRatio)
+	if config.Tracing.Enabled {
+		check(config.Tracing.Exporter == "stdout" || config.Tracing.Exporter == "otlp", "tracing.exporter", "must be stdout or otlp, got %q", config.Tracing.Exporter)
+	}
+	_, ok := tlsVersions[config.TLS.MinVersion]
+	check(ok, "tls.min-version", "unknown version %q", config.TLS.MinVersion)
+	if config.TLS.ClientAuth != "" {
+		_, ok := clientAuthTypes[config.TLS.ClientAuth]
+		check(ok, "tls.client-auth", "unknown value %q", config.TLS.ClientAuth)
+	}
+	check((config.TLS.Cert == "") == (config.TLS.Key == ""), "tls", "cert and key must be set together")
+	if config.HTTP.H2C && !slices.Contains(config.TLS.ALPN, "h2") {
+		// ALPN 不含 h2 时服务不启用 HTTP/2，h2c 同样不生效
+		secure := len(config.Listeners) == 0 && config.TLS.Cert != ""
+		for _, listener := range config.Listeners {
+			secure = secure || listener.TLS && !listener.Redirect
+		}
+		check(!secure, "http.h2c", "requires \"h2\" in tls.alpn when serving TLS, got %q", config.TLS.ALPN)
+	}
+	return errors.Join(errs...)
+}
+
+// validateDSN 校验数据库连接串，支持 MySQL 格式、file: 及 scheme:// 形式的 URL，scheme 需有对应的方言
+func validateDSN(dsn string) error {
+	if _, _, err := orm.ParseDSN(dsn); err != nil {
+		return err
+	}
+	if strings.HasPrefix(dsn, "file:") {
+		return nil
+	}
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		if u.Host == "" && u.Opaque == "" && u.Path == "" {
+			return fmt.Errorf("missing host or path")
+		}
+		return nil
+	}
+	match := mysqlDSN.FindStringSubmatch(dsn)
+	if match == nil {
+		return fmt.Errorf("expected [user[:password]@][net[(addr)]]/dbname[?param=value]")
+	}
+	if _, err := url.ParseQuery(match[1]); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
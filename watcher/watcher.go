@@ -62,15 +62,21 @@ func (watcher *Watcher) Init() error {
 	})
 }
 
-// Watch 开始监听
+// Watch 开始监听，Close 后返回
 func (watcher *Watcher) Watch() {
 	for {
 		select {
-		case event := <-watcher.watcher.Events:
+		case event, ok := <-watcher.watcher.Events:
+			if !ok {
+				return
+			}
 			if watcher.isInterested(event) {
 				watcher.Events <- event
 			}
-		case err := <-watcher.watcher.Errors:
+		case err, ok := <-watcher.watcher.Errors:
+			if !ok {
+				return
+			}
 			watcher.Errs <- err
 		}
 	}
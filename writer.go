@@ -0,0 +1,79 @@
+package cc
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// StatusWriter 记录响应状态码及响应大小的 http.ResponseWriter
+type StatusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WrapWriter 包装 http.ResponseWriter，已包装时直接返回
+func WrapWriter(w http.ResponseWriter) *StatusWriter {
+	if writer, ok := w.(*StatusWriter); ok {
+		return writer
+	}
+	return &StatusWriter{ResponseWriter: w}
+}
+
+// WriteHeader 实现 http.ResponseWriter 接口
+func (writer *StatusWriter) WriteHeader(code int) {
+	if writer.status == 0 {
+		writer.status = code
+	}
+	writer.ResponseWriter.WriteHeader(code)
+}
+
+// Write 实现 http.ResponseWriter 接口
+func (writer *StatusWriter) Write(b []byte) (int, error) {
+	if writer.status == 0 {
+		writer.status = http.StatusOK
+	}
+	n, err := writer.ResponseWriter.Write(b)
+	writer.size += int64(n)
+	return n, err
+}
+
+// Status 响应状态码，未写入时为 0
+func (writer *StatusWriter) Status() int {
+	return writer.status
+}
+
+// Size 已写入的响应体大小
+func (writer *StatusWriter) Size() int64 {
+	return writer.size
+}
+
+// Written 是否已写入响应
+func (writer *StatusWriter) Written() bool {
+	return writer.status != 0
+}
+
+// Flush 实现 http.Flusher 接口
+func (writer *StatusWriter) Flush() {
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		if writer.status == 0 {
+			writer.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker 接口
+func (writer *StatusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := writer.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("the underlying response writer does not support hijacking")
+}
+
+// Unwrap 供 http.ResponseController 获取原始 ResponseWriter
+func (writer *StatusWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}